@@ -0,0 +1,597 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const header = "// Code generated by gen-generators; DO NOT EDIT."
+
+const generatorPkg = "github.com/peterzeller/go-stateful-test/generator"
+
+// basicGenerators are the generators for the predeclared types
+var basicGenerators = map[string]string{
+	"bool":       "generator.Bool()",
+	"int":        "generator.Int()",
+	"int8":       "generator.Int8()",
+	"int16":      "generator.Int16()",
+	"int32":      "generator.Int32()",
+	"rune":       "generator.Int32()",
+	"int64":      "generator.Int64()",
+	"uint":       "generator.UInt()",
+	"uint8":      "generator.UInt8()",
+	"byte":       "generator.UInt8()",
+	"uint16":     "generator.UInt16()",
+	"uint32":     "generator.UInt32()",
+	"uint64":     "generator.UInt64()",
+	"uintptr":    "generator.Uintptr()",
+	"float32":    "generator.Float32()",
+	"float64":    "generator.Float64()",
+	"complex64":  "generator.Complex64()",
+	"complex128": "generator.Complex128()",
+	"string":     "generator.String()",
+}
+
+// typeDecl is a type declared in the package
+type typeDecl struct {
+	spec *ast.TypeSpec
+	file *ast.File
+}
+
+// pkgInfo contains the declarations of the parsed package
+type pkgInfo struct {
+	fset  *token.FileSet
+	name  string
+	types map[string]typeDecl
+	// typeOrder contains the type names in the order of declaration
+	typeOrder []string
+	// methods contains the names of the methods with value receivers by type name
+	methods map[string]map[string]bool
+	// ptrMethods contains the names of the methods with pointer receivers by type name
+	ptrMethods map[string]map[string]bool
+}
+
+// generate returns the source code of the generators for the given types of the package in dir.
+func generate(dir string, typeNames []string) ([]byte, error) {
+	pkg, err := parsePackage(dir)
+	if err != nil {
+		return nil, err
+	}
+	w := &writer{
+		pkg:     pkg,
+		imports: map[string]string{generatorPkg: ""},
+		queued:  make(map[string]bool),
+	}
+	for _, name := range typeNames {
+		if _, ok := pkg.types[name]; !ok {
+			return nil, fmt.Errorf("type %s not found in package %s", name, pkg.name)
+		}
+		w.enqueue(name)
+	}
+	for i := 0; i < len(w.queue); i++ {
+		if err := w.writeFunc(w.queue[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	var out bytes.Buffer
+	_, _ = fmt.Fprintf(&out, "%s\n\npackage %s\n\nimport (\n", header, pkg.name)
+	paths := make([]string, 0, len(w.imports))
+	for path := range w.imports {
+		paths = append(paths, path)
+	}
+	// standard library first, like goimports
+	sort.Slice(paths, func(i, j int) bool {
+		si, sj := isStdLib(paths[i]), isStdLib(paths[j])
+		if si != sj {
+			return si
+		}
+		return paths[i] < paths[j]
+	})
+	for i, path := range paths {
+		if i > 0 && isStdLib(paths[i-1]) && !isStdLib(path) {
+			out.WriteString("\n")
+		}
+		_, _ = fmt.Fprintf(&out, "%s %q\n", w.imports[path], path)
+	}
+	out.WriteString(")\n")
+	out.Write(w.buf.Bytes())
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %w\n%s", err, out.String())
+	}
+	return src, nil
+}
+
+func isStdLib(path string) bool {
+	return !strings.Contains(strings.Split(path, "/")[0], ".")
+}
+
+// parsePackage parses the non-test files of the package in dir, except for files generated by this tool
+// and files excluded by build constraints for the current GOOS, GOARCH, and build tags.
+func parsePackage(dir string) (*pkgInfo, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	pkg := &pkgInfo{
+		fset:       token.NewFileSet(),
+		types:      make(map[string]typeDecl),
+		methods:    make(map[string]map[string]bool),
+		ptrMethods: make(map[string]map[string]bool),
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		match, err := build.Default.MatchFile(dir, name)
+		if err != nil {
+			return nil, err
+		}
+		if !match {
+			continue
+		}
+		file, err := parser.ParseFile(pkg.fset, filepath.Join(dir, name), nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		if isGenerated(file) {
+			continue
+		}
+		if pkg.name == "" {
+			pkg.name = file.Name.Name
+		} else if pkg.name != file.Name.Name {
+			return nil, fmt.Errorf("found packages %s and %s in %s", pkg.name, file.Name.Name, dir)
+		}
+		pkg.addDecls(file)
+	}
+	if pkg.name == "" {
+		return nil, fmt.Errorf("no Go files found in %s", dir)
+	}
+	return pkg, nil
+}
+
+func isGenerated(file *ast.File) bool {
+	for _, c := range file.Comments {
+		if c.Pos() > file.Package {
+			break
+		}
+		if strings.HasPrefix(c.Text(), strings.TrimPrefix(header, "// ")) {
+			return true
+		}
+	}
+	return false
+}
+
+func (pkg *pkgInfo) addDecls(file *ast.File) {
+	for _, decl := range file.Decls {
+		switch decl := decl.(type) {
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				if ts, ok := spec.(*ast.TypeSpec); ok {
+					pkg.types[ts.Name.Name] = typeDecl{spec: ts, file: file}
+					pkg.typeOrder = append(pkg.typeOrder, ts.Name.Name)
+				}
+			}
+		case *ast.FuncDecl:
+			if decl.Recv == nil || len(decl.Recv.List) != 1 {
+				continue
+			}
+			methods := pkg.methods
+			recv := decl.Recv.List[0].Type
+			if star, ok := recv.(*ast.StarExpr); ok {
+				methods = pkg.ptrMethods
+				recv = star.X
+			}
+			ident, ok := recv.(*ast.Ident)
+			if !ok {
+				continue
+			}
+			if methods[ident.Name] == nil {
+				methods[ident.Name] = make(map[string]bool)
+			}
+			methods[ident.Name][decl.Name.Name] = true
+		}
+	}
+}
+
+// isArbitrary returns true if the type has a Generator method, which is assumed to implement generator.Arbitrary.
+func (pkg *pkgInfo) isArbitrary(name string) bool {
+	return pkg.methods[name]["Generator"]
+}
+
+// implementation is a type of the package that implements an interface
+type implementation struct {
+	name    string
+	pointer bool
+}
+
+// implementations returns the types of the package that have all methods of the interface, in order of declaration.
+// Methods are only compared by name, which is enough for sum-type style interfaces.
+func (pkg *pkgInfo) implementations(iface *ast.InterfaceType) ([]implementation, error) {
+	var methods []string
+	for _, m := range iface.Methods.List {
+		if len(m.Names) == 0 {
+			return nil, fmt.Errorf("embedded interfaces are not supported")
+		}
+		for _, n := range m.Names {
+			methods = append(methods, n.Name)
+		}
+	}
+	if len(methods) == 0 {
+		return nil, fmt.Errorf("empty interfaces are not supported")
+	}
+	var res []implementation
+	for _, name := range pkg.typeOrder {
+		decl := pkg.types[name]
+		if _, ok := decl.spec.Type.(*ast.InterfaceType); ok || decl.spec.TypeParams != nil {
+			continue
+		}
+		valueOk, ptrOk := true, true
+		for _, m := range methods {
+			if !pkg.methods[name][m] {
+				valueOk = false
+				if !pkg.ptrMethods[name][m] {
+					ptrOk = false
+				}
+			}
+		}
+		if valueOk || ptrOk {
+			res = append(res, implementation{name: name, pointer: !valueOk})
+		}
+	}
+	if len(res) == 0 {
+		return nil, fmt.Errorf("no implementations found")
+	}
+	return res, nil
+}
+
+// components returns the types of the package that the type with the given name is built from.
+func (pkg *pkgInfo) components(name string) []string {
+	if pkg.isArbitrary(name) {
+		// the generator of the type does not use the components
+		return nil
+	}
+	decl := pkg.types[name]
+	var res []string
+	if iface, ok := decl.spec.Type.(*ast.InterfaceType); ok {
+		impls, _ := pkg.implementations(iface)
+		for _, impl := range impls {
+			res = append(res, impl.name)
+		}
+		return res
+	}
+	typeExprs := []ast.Expr{decl.spec.Type}
+	if st, ok := decl.spec.Type.(*ast.StructType); ok {
+		// only look at the types of the fields, not their names
+		typeExprs = typeExprs[:0]
+		for _, f := range st.Fields.List {
+			typeExprs = append(typeExprs, f.Type)
+		}
+	}
+	for _, t := range typeExprs {
+		ast.Inspect(t, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.SelectorExpr:
+				// types of other packages
+				return false
+			case *ast.Ident:
+				if _, ok := pkg.types[n.Name]; ok {
+					res = append(res, n.Name)
+				}
+			}
+			return true
+		})
+	}
+	return res
+}
+
+// reaches returns true if the type with the given name contains one of the targets,
+// without going through the types in skip.
+func (pkg *pkgInfo) reaches(name string, targets func(string) bool, skip map[string]string) bool {
+	visited := make(map[string]bool)
+	var visit func(n string) bool
+	visit = func(n string) bool {
+		for _, c := range pkg.components(n) {
+			if targets(c) {
+				return true
+			}
+			if _, ok := skip[c]; ok || visited[c] {
+				continue
+			}
+			visited[c] = true
+			if visit(c) {
+				return true
+			}
+		}
+		return false
+	}
+	return visit(name)
+}
+
+// writer writes the generator functions
+type writer struct {
+	pkg *pkgInfo
+	// imports of the generated file by path
+	imports map[string]string
+	queue   []string
+	queued  map[string]bool
+	buf     bytes.Buffer
+}
+
+func (w *writer) enqueue(name string) {
+	if !w.queued[name] {
+		w.queued[name] = true
+		w.queue = append(w.queue, name)
+	}
+}
+
+// funcName returns the name of the generator function for a type
+func funcName(typeName string) string {
+	if unicode.IsUpper([]rune(typeName)[0]) {
+		return "Gen" + typeName
+	}
+	return "gen" + upperFirst(typeName)
+}
+
+func upperFirst(s string) string {
+	r := []rune(s)
+	return string(unicode.ToUpper(r[0])) + string(r[1:])
+}
+
+func (w *writer) writeFunc(name string) error {
+	body, err := w.typeBody(name, map[string]string{})
+	if err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(&w.buf, "\n// %s returns a generator for %s.\nfunc %s() generator.Generator[%s, interface{}] {\n%s}\n",
+		funcName(name), name, funcName(name), name, body)
+	return nil
+}
+
+// typeBody returns the statements that return the generator for the named type.
+// inProgress maps the types whose generator is currently being built to the variable containing their recursive generator.
+func (w *writer) typeBody(name string, inProgress map[string]string) (string, error) {
+	decl := w.pkg.types[name]
+	if decl.spec.TypeParams != nil {
+		return "", fmt.Errorf("type %s: generic types are not supported", name)
+	}
+	if w.pkg.reaches(name, func(c string) bool { return c == name }, inProgress) {
+		self := "self" + upperFirst(name)
+		inner := make(map[string]string, len(inProgress)+1)
+		for k, v := range inProgress {
+			inner[k] = v
+		}
+		inner[name] = self
+		body, err := w.nonRecursiveBody(name, decl, inner)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("return generator.Recursive(func(%s generator.Generator[%s, interface{}]) generator.Generator[%s, interface{}] {\n%s})\n",
+			self, name, name, body), nil
+	}
+	return w.nonRecursiveBody(name, decl, inProgress)
+}
+
+func (w *writer) nonRecursiveBody(name string, decl typeDecl, inProgress map[string]string) (string, error) {
+	switch t := decl.spec.Type.(type) {
+	case *ast.StructType:
+		return w.structBody(name, decl, t, inProgress)
+	case *ast.InterfaceType:
+		impls, err := w.pkg.implementations(t)
+		if err != nil {
+			return "", fmt.Errorf("interface %s: %w", name, err)
+		}
+		var b strings.Builder
+		b.WriteString("return generator.UntypedR(generator.OneOf(\n")
+		for _, impl := range impls {
+			g, err := w.typeRef(impl.name, inProgress)
+			if err != nil {
+				return "", err
+			}
+			ref := "v"
+			if impl.pointer {
+				ref = "&v"
+			}
+			_, _ = fmt.Fprintf(&b, "generator.UntypedR(generator.Map(%s, func(v %s) %s {\nreturn %s\n})),\n", g, impl.name, name, ref)
+		}
+		b.WriteString("))\n")
+		return b.String(), nil
+	default:
+		g, err := w.typeExpr(decl.spec.Type, decl.file, inProgress)
+		if err != nil {
+			return "", fmt.Errorf("type %s: %w", name, err)
+		}
+		if decl.spec.Assign.IsValid() {
+			// alias
+			return fmt.Sprintf("return generator.UntypedR(%s)\n", g), nil
+		}
+		return fmt.Sprintf("return generator.UntypedR(generator.Map(%s, func(v %s) %s {\nreturn %s(v)\n}))\n",
+			g, w.print(decl.spec.Type), name, name), nil
+	}
+}
+
+func (w *writer) structBody(name string, decl typeDecl, t *ast.StructType, inProgress map[string]string) (string, error) {
+	var b strings.Builder
+	n := 0
+	for _, field := range t.Fields.List {
+		if field.Tag != nil {
+			tagValue, err := strconv.Unquote(field.Tag.Value)
+			if err != nil {
+				return "", err
+			}
+			switch tag := reflect.StructTag(tagValue).Get("gen"); tag {
+			case "":
+			case "-", "skip":
+				continue
+			default:
+				return "", fmt.Errorf("struct %s: gen tag %q is not supported, only skip", name, tag)
+			}
+		}
+		fieldNames := field.Names
+		if len(fieldNames) == 0 {
+			fieldNames = []*ast.Ident{embeddedName(field.Type)}
+		}
+		g, err := w.typeExpr(field.Type, decl.file, inProgress)
+		if err != nil {
+			return "", fmt.Errorf("struct %s, field %s: %w", name, fieldNames[0].Name, err)
+		}
+		for _, f := range fieldNames {
+			if n == 0 {
+				_, _ = fmt.Fprintf(&b, "g0 := generator.Map(%s, func(v %s) %s {\nvar s %s\ns.%s = v\nreturn s\n})\n",
+					g, w.print(field.Type), name, name, f.Name)
+			} else {
+				_, _ = fmt.Fprintf(&b, "g%d := generator.Zip(g%d, %s, func(s %s, v %s) %s {\ns.%s = v\nreturn s\n})\n",
+					n, n-1, g, name, w.print(field.Type), name, f.Name)
+			}
+			n++
+		}
+	}
+	if n == 0 {
+		return fmt.Sprintf("return generator.UntypedR(generator.Constant(%s{}))\n", name), nil
+	}
+	_, _ = fmt.Fprintf(&b, "return generator.UntypedR(g%d)\n", n-1)
+	return b.String(), nil
+}
+
+// embeddedName returns the field name of an embedded field
+func embeddedName(t ast.Expr) *ast.Ident {
+	switch t := t.(type) {
+	case *ast.StarExpr:
+		return embeddedName(t.X)
+	case *ast.SelectorExpr:
+		return t.Sel
+	case *ast.Ident:
+		return t
+	default:
+		return ast.NewIdent("?")
+	}
+}
+
+// typeRef returns the generator for a type of the package:
+// the variable of the recursive generator, generator.Any for types that define their own generator, an inlined generator if it refers to a type that is in progress,
+// or a call to the generator function of the type.
+func (w *writer) typeRef(name string, inProgress map[string]string) (string, error) {
+	if self, ok := inProgress[name]; ok {
+		return self, nil
+	}
+	if w.pkg.isArbitrary(name) {
+		return fmt.Sprintf("generator.Any[%s]()", name), nil
+	}
+	inCycle := w.pkg.reaches(name, func(c string) bool {
+		_, ok := inProgress[c]
+		return ok
+	}, inProgress)
+	if inCycle {
+		body, err := w.typeBody(name, inProgress)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("func() generator.Generator[%s, interface{}] {\n%s}()", name, body), nil
+	}
+	w.enqueue(name)
+	return funcName(name) + "()", nil
+}
+
+// typeExpr returns the generator for a type expression in the given file.
+func (w *writer) typeExpr(t ast.Expr, file *ast.File, inProgress map[string]string) (string, error) {
+	switch t := t.(type) {
+	case *ast.Ident:
+		if _, ok := w.pkg.types[t.Name]; ok {
+			return w.typeRef(t.Name, inProgress)
+		}
+		if g, ok := basicGenerators[t.Name]; ok {
+			return g, nil
+		}
+		return "", fmt.Errorf("unsupported type %s", t.Name)
+	case *ast.ParenExpr:
+		return w.typeExpr(t.X, file, inProgress)
+	case *ast.StarExpr:
+		elem, err := w.typeExpr(t.X, file, inProgress)
+		if err != nil {
+			return "", err
+		}
+		elemType := w.print(t.X)
+		return fmt.Sprintf("generator.Frequency(\ngenerator.Weighted(1, generator.UntypedR(generator.Constant[*%s](nil))),\n"+
+			"generator.Weighted(3, generator.UntypedR(generator.Map(%s, func(v %s) *%s {\nreturn &v\n}))))",
+			elemType, elem, elemType, elemType), nil
+	case *ast.ArrayType:
+		elem, err := w.typeExpr(t.Elt, file, inProgress)
+		if err != nil {
+			return "", err
+		}
+		if t.Len == nil {
+			return fmt.Sprintf("generator.Slice(%s)", elem), nil
+		}
+		arrayType := w.print(t)
+		return fmt.Sprintf("generator.Map(generator.SliceFixedLength(%s, %s), func(s []%s) %s {\nvar a %s\ncopy(a[:], s)\nreturn a\n})",
+			elem, w.print(t.Len), w.print(t.Elt), arrayType, arrayType), nil
+	case *ast.MapType:
+		key, err := w.typeExpr(t.Key, file, inProgress)
+		if err != nil {
+			return "", err
+		}
+		value, err := w.typeExpr(t.Value, file, inProgress)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("generator.DictMut(%s, %s)", key, value), nil
+	case *ast.SelectorExpr:
+		pkgIdent, ok := t.X.(*ast.Ident)
+		if !ok {
+			return "", fmt.Errorf("unsupported type %s", w.print(t))
+		}
+		path, name, ok := importOf(file, pkgIdent.Name)
+		if !ok {
+			return "", fmt.Errorf("package %s of type %s not found in imports", pkgIdent.Name, w.print(t))
+		}
+		w.imports[path] = name
+		return fmt.Sprintf("generator.Any[%s]()", w.print(t)), nil
+	default:
+		return "", fmt.Errorf("unsupported type %s", w.print(t))
+	}
+}
+
+// importOf finds the import of a file with the given package name.
+// It returns the path and the explicit name of the import, if any.
+func importOf(file *ast.File, pkgName string) (string, string, bool) {
+	for _, imp := range file.Imports {
+		path, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			continue
+		}
+		if imp.Name != nil {
+			if imp.Name.Name == pkgName {
+				return path, pkgName, true
+			}
+			continue
+		}
+		if path[strings.LastIndex(path, "/")+1:] == pkgName {
+			return path, "", true
+		}
+	}
+	return "", "", false
+}
+
+// print returns the source code of a type expression
+func (w *writer) print(t ast.Expr) string {
+	var b bytes.Buffer
+	if err := printer.Fprint(&b, w.pkg.fset, t); err != nil {
+		panic(err)
+	}
+	return b.String()
+}
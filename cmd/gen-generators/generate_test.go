@@ -0,0 +1,62 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestExamplesUpToDate checks that the generated generators in the examples package are up to date.
+func TestExamplesUpToDate(t *testing.T) {
+	src, err := generate("../../examples", []string{"Drawing", "Expr"})
+	require.NoError(t, err)
+	expected, err := os.ReadFile("../../examples/shapes_generators.go")
+	require.NoError(t, err)
+	require.Equal(t, string(expected), string(src), "run go generate in the examples directory")
+}
+
+func TestGenerateErrors(t *testing.T) {
+	tests := []struct {
+		src      string
+		typ      string
+		expected string
+	}{
+		{"type A struct{ X int }", "B", "type B not found in package p"},
+		{"type A struct{ F func() }", "A", "struct A, field F: unsupported type func()"},
+		{"type A struct{ X int `gen:\"min=1\"` }", "A", `struct A: gen tag "min=1" is not supported, only skip`},
+		{"type A interface{ f() }", "A", "interface A: no implementations found"},
+		{"type A interface{ }", "A", "interface A: empty interfaces are not supported"},
+		{"type A[T any] struct{ X T }", "A", "type A: generic types are not supported"},
+		{"type A struct{ B B }\ntype B struct{ C chan int }", "A", "struct B, field C: unsupported type chan int"},
+	}
+	for _, test := range tests {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "p.go"), []byte("package p\n\n"+test.src+"\n"), 0o644))
+		_, err := generate(dir, []string{test.typ})
+		require.EqualError(t, err, test.expected, test.src)
+	}
+}
+
+func TestGenerateBuildConstraints(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"p.go": "package p\n\ntype A struct{ X int }\n",
+		// ignored files often belong to a different package
+		"tool.go":         "//go:build ignore\n\npackage main\n\ntype A struct{ F func() }\n",
+		"p_nonexist.go":   "//go:build nonexistent\n\npackage p\n\ntype A struct{ F func() }\n",
+		"p_plan9_mips.go": "package p\n\ntype A struct{ F func() }\n",
+	}
+	for name, src := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(src), 0o644))
+	}
+	src, err := generate(dir, []string{"A"})
+	require.NoError(t, err)
+	require.Contains(t, string(src), "generator.Int()")
+}
+
+func TestFuncName(t *testing.T) {
+	require.Equal(t, "GenAccount", funcName("Account"))
+	require.Equal(t, "genAccount", funcName("account"))
+}
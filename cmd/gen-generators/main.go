@@ -0,0 +1,69 @@
+// Command gen-generators generates type-safe generators for the types of a Go package.
+//
+// It is meant to be used with go:generate:
+//
+//	//go:generate go run github.com/peterzeller/go-stateful-test/cmd/gen-generators -type=Account,Shape
+//
+// For every type T, it generates a function GenT (or genT for unexported types) returning a generator.Generator[T, interface{}].
+// The random values of the generators are erased to interface{} with generator.UntypedR,
+// because the precise type of the random values of combined generators is long and changes with the structure of T.
+// The generators can be used with pick.Val and the other combinators like any other generator,
+// but their random values should only be passed back to the same generator.
+// The generators are built from the combinators of the generator package, so they support random generation, shrinking, and enumeration:
+//
+//   - structs use Map and Zip for the fields (fields with the tag `gen:"-"` or `gen:"skip"` are left at their zero value)
+//   - slices, arrays, maps, and pointers use Slice, SliceFixedLength, DictMut, and nil or a pointer to a new value
+//   - interfaces use OneOf with the types of the package that implement all methods of the interface
+//   - recursive types use Recursive, so that the size of the generated values is limited
+//   - types with a Generator method (see generator.Arbitrary) and types from other packages use generator.Any
+//
+// Generators for the types of the package used by the given types are generated as well.
+//
+// Only the files of the package that match the build constraints for the current GOOS and GOARCH are read.
+// Additional build tags can be set with the -tags flag.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/build"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	typeNames := flag.String("type", "", "comma separated list of type names; must be set")
+	output := flag.String("output", "", "output file name; default <type>_generators.go for the first type")
+	tags := flag.String("tags", "", "comma separated list of build tags to apply when reading the package")
+	flag.Parse()
+	if *typeNames == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	dir := "."
+	if args := flag.Args(); len(args) > 0 {
+		dir = args[0]
+	}
+	if *tags != "" {
+		build.Default.BuildTags = strings.Split(*tags, ",")
+	}
+	types := strings.Split(*typeNames, ",")
+	if *output == "" {
+		*output = strings.ToLower(types[0]) + "_generators.go"
+	}
+	path := *output
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+
+	src, err := generate(dir, types)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gen-generators: %v\n", err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(path, src, 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "gen-generators: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,62 @@
+package examples
+
+import (
+	"testing"
+
+	"github.com/peterzeller/go-stateful-test/generator"
+	"github.com/peterzeller/go-stateful-test/pick"
+	"github.com/peterzeller/go-stateful-test/quickcheck"
+	"github.com/peterzeller/go-stateful-test/smallcheck"
+	"github.com/peterzeller/go-stateful-test/statefulTest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssumeQuick(t *testing.T) {
+	quickcheck.Run(t, quickcheck.Config{}, func(t statefulTest.T) {
+		x := pick.Val(t, generator.IntRange(-1000, 1000))
+		y := pick.Val(t, generator.IntRange(-1000, 1000))
+		t.Assume(x < y)
+		require.Greater(t, y-x, 0)
+	})
+}
+
+func TestAssumeShrinkQuick(t *testing.T) {
+	log := expectError(t, func(t quickcheck.TestingT) {
+		quickcheck.Run(t, quickcheck.Config{NumberOfRuns: 1000}, func(t statefulTest.T) {
+			x := pick.Val(t, generator.IntRange(0, 10000))
+			t.Assume(x != 100)
+			t.Logf("x = %d", x)
+			require.Less(t, x, 100)
+		})
+	})
+	// the discarded run with x = 100 is not a counterexample
+	require.Contains(t, log, "x = 101\n")
+}
+
+func TestTooManyDiscardsQuick(t *testing.T) {
+	log := expectError(t, func(t quickcheck.TestingT) {
+		quickcheck.Run(t, quickcheck.Config{NumberOfRuns: 10, MaxDiscardRatio: 2}, func(t statefulTest.T) {
+			x := pick.Val(t, generator.Int())
+			t.Assume(x == 123456)
+		})
+	})
+	require.Contains(t, log, "gave up after 0 passed and 21 discarded test runs")
+}
+
+func TestAssumeSmall(t *testing.T) {
+	smallcheck.Run(t, smallcheck.Config{}, func(t statefulTest.T) {
+		x := pick.Val(t, generator.IntRange(-1000, 1000))
+		y := pick.Val(t, generator.IntRange(-1000, 1000))
+		t.Assume(x < y)
+		require.Greater(t, y-x, 0)
+	})
+}
+
+func TestTooManyDiscardsSmall(t *testing.T) {
+	log := expectError(t, func(t quickcheck.TestingT) {
+		smallcheck.Run(t, smallcheck.Config{}, func(t statefulTest.T) {
+			t.Discard()
+		})
+	})
+	require.Contains(t, log, "Gave up at depth 1: 0 test runs passed, 1 discarded")
+}
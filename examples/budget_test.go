@@ -0,0 +1,47 @@
+package examples
+
+import (
+	"testing"
+	"time"
+
+	"github.com/peterzeller/go-stateful-test/generator"
+	"github.com/peterzeller/go-stateful-test/pick"
+	"github.com/peterzeller/go-stateful-test/quickcheck"
+	"github.com/peterzeller/go-stateful-test/smallcheck"
+	"github.com/peterzeller/go-stateful-test/statefulTest"
+	"github.com/stretchr/testify/require"
+)
+
+func sumProperty(t statefulTest.T) {
+	sum := 0
+	for t.HasMore() {
+		sum += pick.Val(t, generator.IntRange(0, 9))
+	}
+	require.True(t, sum >= 0)
+}
+
+func TestSmallCheckMaxRuns(t *testing.T) {
+	l := &logT{}
+	smallcheck.Run(l, smallcheck.Config{Depth: 20, MaxRuns: 50}, sumProperty)
+	require.False(t, l.Failed())
+	require.Regexp(t, `smallcheck: budget exhausted after 50 test runs in \S+, completed depth \d+ \(exhaustive = false\), depth \d+ was partially explored`, l.log.String())
+}
+
+func TestSmallCheckMaxDuration(t *testing.T) {
+	l := &logT{}
+	start := time.Now()
+	smallcheck.Run(l, smallcheck.Config{Depth: 100, MaxDuration: 50 * time.Millisecond}, func(t statefulTest.T) {
+		time.Sleep(time.Millisecond)
+		sumProperty(t)
+	})
+	require.Less(t, time.Since(start), 5*time.Second)
+	require.False(t, l.Failed())
+	require.Contains(t, l.log.String(), "smallcheck: budget exhausted after")
+}
+
+func TestSmallCheckBudgetStillFindsErrors(t *testing.T) {
+	log := expectError(t, func(t quickcheck.TestingT) {
+		smallcheck.Run(t, smallcheck.Config{MaxRuns: 1000}, QueueProperty)
+	})
+	require.Contains(t, log, "Test failed at depth")
+}
@@ -0,0 +1,104 @@
+package examples
+
+import (
+	"testing"
+
+	"github.com/peterzeller/go-fun/hash"
+	"github.com/peterzeller/go-stateful-test/generator"
+	"github.com/peterzeller/go-stateful-test/pick"
+	"github.com/peterzeller/go-stateful-test/quickcheck"
+	"github.com/peterzeller/go-stateful-test/statefulTest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChoiceSequenceInts(t *testing.T) {
+	log := expectError(t, func(t quickcheck.TestingT) {
+		quickcheck.Run(t, quickcheck.Config{Seed: 1, Engine: quickcheck.ChoiceSequenceEngine}, func(t statefulTest.T) {
+			x := pick.Val(t, generator.IntRange(0, 1000))
+			y := pick.Val(t, generator.IntRange(0, 1000))
+			t.Logf("x = %d, y = %d", x, y)
+			require.True(t, x+y < 10)
+		})
+	})
+	require.Contains(t, log, "x = 0, y = 10\n")
+}
+
+func TestChoiceSequenceFlatMap(t *testing.T) {
+	log := expectError(t, func(t quickcheck.TestingT) {
+		quickcheck.Run(t, quickcheck.Config{Seed: 1, Engine: quickcheck.ChoiceSequenceEngine}, func(t statefulTest.T) {
+			// the elements depend on the generated bound
+			gen := generator.FlatMap(generator.IntRange(1, 100), func(bound int) generator.Generator[[]int, []int64] {
+				return generator.Slice(generator.IntRange(0, bound))
+			})
+			s := pick.Val(t, gen)
+			t.Logf("s = %v", s)
+			sum := 0
+			for _, x := range s {
+				sum += x
+			}
+			require.Less(t, sum, 50)
+		})
+	})
+	// shrinking the bound keeps the elements valid, so a single element remains
+	require.Contains(t, log, "s = [50]\n")
+}
+
+func TestChoiceSequenceSet(t *testing.T) {
+	log := expectError(t, func(t quickcheck.TestingT) {
+		quickcheck.Run(t, quickcheck.Config{Seed: 1, Engine: quickcheck.ChoiceSequenceEngine}, func(t statefulTest.T) {
+			s := pick.Val(t, generator.Set(generator.IntRange(0, 100), hash.Num[int]()))
+			t.Logf("s = %v", s)
+			require.False(t, s.Contains(7))
+		})
+	})
+	require.Contains(t, log, "s = [7]\n")
+}
+
+func TestChoiceSequenceAnonGenerator(t *testing.T) {
+	// a generator without a Shrink function
+	evenGen := &generator.AnonGenerator[int, int]{
+		GenName: "even",
+		GenRandom: func(rnd generator.Rand, size int) int {
+			return 2 * rnd.R().Intn(1000)
+		},
+		GenRValue: func(r int) (int, bool) {
+			return r, true
+		},
+	}
+	log := expectError(t, func(t quickcheck.TestingT) {
+		quickcheck.Run(t, quickcheck.Config{Seed: 1, Engine: quickcheck.ChoiceSequenceEngine}, func(t statefulTest.T) {
+			x := pick.Val[int, int](t, evenGen)
+			t.Logf("x = %d", x)
+			require.Less(t, x, 100)
+		})
+	})
+	require.Contains(t, log, "x = 100\n")
+}
+
+func TestChoiceSequenceFailureDB(t *testing.T) {
+	db := quickcheck.FailureDB{Dir: t.TempDir()}
+	prop := func(t statefulTest.T) {
+		x := pick.Val(t, generator.IntRange(0, 1000))
+		t.Logf("x = %d", x)
+		require.Less(t, x, 5)
+	}
+	cfg := quickcheck.Config{Seed: 42, FailureDBDir: db.Dir, Engine: quickcheck.ChoiceSequenceEngine}
+	first := runNamed("TestChoiceSequenceFailureDB", func(t quickcheck.TestingT) {
+		quickcheck.Run(t, cfg, prop)
+	})
+	require.True(t, first.Failed())
+	stored, err := db.Load("TestChoiceSequenceFailureDB")
+	require.NoError(t, err)
+	require.Len(t, stored, 1)
+	require.Equal(t, quickcheck.ChoiceSequenceEngine, stored[0].Engine)
+	require.NotEmpty(t, stored[0].Choices)
+
+	// the stored choice sequence replays the shrunk run directly
+	cfg.Seed = 7
+	second := runNamed("TestChoiceSequenceFailureDB", func(t quickcheck.TestingT) {
+		quickcheck.Run(t, cfg, prop)
+	})
+	require.True(t, second.Failed())
+	require.Contains(t, second.log.String(), "Stored counterexample")
+	require.Contains(t, second.log.String(), "x = 5\n")
+}
@@ -52,28 +52,26 @@ func QueueProperty(t statefulTest.T) {
 
 	// repeat commands
 	for t.HasMore() {
-		pick.Switch(t, pick.Cases{
-			"get": func() {
-				// Test q.Get:
-				if q.Size() == 0 {
-					// skip if queue is empty
-					return
-				}
-				i := q.Get()
-				t.Logf("Calling q.Get() -> %d", i)
-				require.Equal(t, model[0], i, "result of q.Get()")
-				model = model[1:]
+		pick.SwitchGuarded(t, pick.GuardedCases{
+			"get": {
+				// Test q.Get, only if the queue is not empty
+				Enabled: func() bool { return q.Size() > 0 },
+				Run: func() {
+					i := q.Get()
+					t.Logf("Calling q.Get() -> %d", i)
+					require.Equal(t, model[0], i, "result of q.Get()")
+					model = model[1:]
+				},
 			},
-			"put": func() {
-				// Test q.Put
-				if q.Size() >= n {
-					// skip if queue is full
-					return
-				}
-				i := pick.Val(t, generator.Int())
-				t.Logf("Calling q.Put(%d)", i)
-				q.Put(i)
-				model = append(model, i)
+			"put": {
+				// Test q.Put, only if the queue is not full
+				Enabled: func() bool { return q.Size() < n },
+				Run: func() {
+					i := pick.Val(t, generator.Int())
+					t.Logf("Calling q.Put(%d)", i)
+					q.Put(i)
+					model = append(model, i)
+				},
 			},
 		})
 		// check invariant
@@ -1,6 +1,7 @@
 package examples
 
 import (
+	"github.com/peterzeller/go-fun/hash"
 	"github.com/peterzeller/go-stateful-test/smallcheck"
 	"github.com/stretchr/testify/assert"
 	"testing"
@@ -50,7 +51,8 @@ func TestStrings(t *testing.T) {
 
 func TestMax3Quick(t *testing.T) {
 	expectError(t, func(t quickcheck.TestingT) {
-		quickcheck.Run(t, quickcheck.Config{}, func(t statefulTest.T) {
+		// the bug needs two equal maximal values, so a fixed seed makes sure it is found
+		quickcheck.Run(t, quickcheck.Config{Seed: 1}, func(t statefulTest.T) {
 			x := pick.Val(t, generator.Int())
 			y := pick.Val(t, generator.Int())
 			z := pick.Val(t, generator.Int())
@@ -63,6 +65,24 @@ func TestMax3Quick(t *testing.T) {
 	})
 }
 
+func TestSeedReplay(t *testing.T) {
+	prop := func(t statefulTest.T) {
+		x := pick.Val(t, generator.Int())
+		y := pick.Val(t, generator.Int())
+		t.Logf("x = %d, y = %d", x, y)
+		require.True(t, x+y < 10)
+	}
+	cfg := quickcheck.Config{Seed: 42}
+	var outputs []string
+	for i := 0; i < 2; i++ {
+		outputs = append(outputs, expectError(t, func(t quickcheck.TestingT) {
+			quickcheck.Run(t, cfg, prop)
+		}))
+	}
+	require.Contains(t, outputs[0], "seed = 42")
+	require.Equal(t, outputs[0], outputs[1], "runs with the same seed should produce the same result")
+}
+
 func TestHasMore(t *testing.T) {
 	out := expectError(t, func(t quickcheck.TestingT) {
 		quickcheck.Run(t, quickcheck.Config{}, func(t statefulTest.T) {
@@ -102,3 +122,36 @@ func TestCleanupQuickcheck(t *testing.T) {
 	})
 	require.Equal(t, 0, count)
 }
+
+func TestSizeSchedule(t *testing.T) {
+	var sizes []int
+	quickcheck.Run(t, quickcheck.Config{NumberOfRuns: 50, MaxSize: 20}, func(t statefulTest.T) {
+		s := pick.Val(t, generator.Slice(generator.Int()))
+		sizes = append(sizes, len(s))
+	})
+	// the first runs use small sizes
+	require.Equal(t, []int{0, 0, 0}, sizes[:3])
+	require.Less(t, sizes[len(sizes)-1], 20)
+
+	log := expectError(t, func(t quickcheck.TestingT) {
+		quickcheck.Run(t, quickcheck.Config{MinSize: 10, MaxSize: 10}, func(t statefulTest.T) {
+			s := pick.Val(t, generator.Slice(generator.Int()))
+			require.Less(t, len(s), 5)
+		})
+	})
+	require.Contains(t, log, "size = 10)")
+}
+
+func TestSetQuick(t *testing.T) {
+	// the first run uses the smallest size, which must not break generators like Set
+	quickcheck.Run(t, quickcheck.Config{}, func(t statefulTest.T) {
+		s := pick.Val(t, generator.Set(generator.IntRange(0, 10), hash.Num[int]()))
+		for it := s.Iterator(); ; {
+			x, ok := it.Next()
+			if !ok {
+				break
+			}
+			require.True(t, 0 <= x && x <= 10)
+		}
+	})
+}
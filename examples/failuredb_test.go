@@ -0,0 +1,133 @@
+package examples
+
+import (
+	"testing"
+
+	"github.com/peterzeller/go-stateful-test/generator"
+	"github.com/peterzeller/go-stateful-test/pick"
+	"github.com/peterzeller/go-stateful-test/quickcheck"
+	"github.com/peterzeller/go-stateful-test/statefulTest"
+	"github.com/stretchr/testify/require"
+)
+
+// namedLogT is a logT with a test name, which enables the failure database.
+type namedLogT struct {
+	logT
+	name string
+}
+
+func (n *namedLogT) Name() string {
+	return n.name
+}
+
+func runNamed(name string, f func(t quickcheck.TestingT)) (t *namedLogT) {
+	t = &namedLogT{name: name}
+	defer func() {
+		if r := recover(); r != nil && !t.Failed() {
+			panic(r)
+		}
+	}()
+	f(t)
+	return t
+}
+
+func TestFailureDBReplay(t *testing.T) {
+	db := quickcheck.FailureDB{Dir: t.TempDir()}
+	prop := func(t statefulTest.T) {
+		x := pick.Val(t, generator.Int())
+		t.Logf("x = %d", x)
+		require.True(t, x < 5)
+	}
+
+	first := runNamed("TestFailureDBReplay", func(t quickcheck.TestingT) {
+		quickcheck.Run(t, quickcheck.Config{Seed: 42, FailureDBDir: db.Dir}, prop)
+	})
+	require.True(t, first.Failed())
+	require.Contains(t, first.log.String(), "x = 5\n")
+	stored, err := db.Load("TestFailureDBReplay")
+	require.NoError(t, err)
+	require.Len(t, stored, 1)
+
+	// the second invocation uses a different seed, but finds the stored counterexample first
+	second := runNamed("TestFailureDBReplay", func(t quickcheck.TestingT) {
+		quickcheck.Run(t, quickcheck.Config{Seed: 7, FailureDBDir: db.Dir}, prop)
+	})
+	require.True(t, second.Failed())
+	require.Contains(t, second.log.String(), "Stored counterexample")
+	require.Contains(t, second.log.String(), "x = 5\n")
+
+	// pruning removes the counterexample
+	require.NoError(t, db.Prune("TestFailureDBReplay", func(c quickcheck.Counterexample) bool {
+		return c.Seed != stored[0].Seed
+	}))
+	stored, err = db.Load("TestFailureDBReplay")
+	require.NoError(t, err)
+	require.Empty(t, stored)
+}
+
+func TestFailureDBPassingCounterexample(t *testing.T) {
+	db := quickcheck.FailureDB{Dir: t.TempDir()}
+	first := runNamed("TestPassing", func(t quickcheck.TestingT) {
+		quickcheck.Run(t, quickcheck.Config{Seed: 42, FailureDBDir: db.Dir}, func(t statefulTest.T) {
+			require.Less(t, pick.Val(t, generator.Int()), 5)
+		})
+	})
+	require.True(t, first.Failed())
+
+	// a stored counterexample that no longer fails does not fail the test
+	res := runNamed("TestPassing", func(t quickcheck.TestingT) {
+		quickcheck.Run(t, quickcheck.Config{FailureDBDir: db.Dir}, func(t statefulTest.T) {
+			pick.Val(t, generator.Int())
+		})
+	})
+	require.False(t, res.Failed())
+	require.NotContains(t, res.log.String(), "stale")
+	stored, err := db.Load("TestPassing")
+	require.NoError(t, err)
+	require.Len(t, stored, 1, "passing counterexamples are kept")
+}
+
+func TestFailureDBStaleCounterexample(t *testing.T) {
+	db := quickcheck.FailureDB{Dir: t.TempDir()}
+	first := runNamed("TestStale", func(t quickcheck.TestingT) {
+		quickcheck.Run(t, quickcheck.Config{Seed: 42, FailureDBDir: db.Dir}, func(t statefulTest.T) {
+			require.Less(t, pick.Val(t, generator.Int()), 5)
+		})
+	})
+	require.True(t, first.Failed())
+
+	// the test now draws more values than the stored counterexample, so it is not replayed
+	res := runNamed("TestStale", func(t quickcheck.TestingT) {
+		quickcheck.Run(t, quickcheck.Config{FailureDBDir: db.Dir}, func(t statefulTest.T) {
+			pick.Val(t, generator.Int())
+			pick.Val(t, generator.Int())
+		})
+	})
+	require.False(t, res.Failed())
+	require.Contains(t, res.log.String(), "removing stale counterexample")
+	stored, err := db.Load("TestStale")
+	require.NoError(t, err)
+	require.Empty(t, stored)
+}
+
+func TestFailureDBStaleChoices(t *testing.T) {
+	db := quickcheck.FailureDB{Dir: t.TempDir()}
+	require.NoError(t, db.Add("TestStaleChoices", quickcheck.Counterexample{
+		Seed:    1,
+		Size:    10,
+		Engine:  quickcheck.ChoiceSequenceEngine,
+		Choices: []uint64{50},
+	}))
+
+	// the stored choice is out of the range of the generator
+	res := runNamed("TestStaleChoices", func(t quickcheck.TestingT) {
+		quickcheck.Run(t, quickcheck.Config{FailureDBDir: db.Dir, Engine: quickcheck.ChoiceSequenceEngine}, func(t statefulTest.T) {
+			pick.Val(t, generator.IntRange(0, 9))
+		})
+	})
+	require.False(t, res.Failed())
+	require.Contains(t, res.log.String(), "removing stale counterexample")
+	stored, err := db.Load("TestStaleChoices")
+	require.NoError(t, err)
+	require.Empty(t, stored)
+}
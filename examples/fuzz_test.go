@@ -0,0 +1,25 @@
+package examples
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/peterzeller/go-stateful-test/generator"
+	"github.com/peterzeller/go-stateful-test/pick"
+	"github.com/peterzeller/go-stateful-test/quickcheck"
+	"github.com/peterzeller/go-stateful-test/statefulTest"
+	"github.com/stretchr/testify/require"
+)
+
+// FuzzSort shows how to run a property as a Go fuzz test.
+// Run it with: go test -fuzz=FuzzSort ./examples
+func FuzzSort(f *testing.F) {
+	quickcheck.Fuzz(f, func(t statefulTest.T) {
+		xs := pick.Val(t, generator.Slice(generator.Int()))
+		sort.Ints(xs)
+		t.Logf("sorted: %v", xs)
+		for i := 1; i < len(xs); i++ {
+			require.LessOrEqual(t, xs[i-1], xs[i])
+		}
+	})
+}
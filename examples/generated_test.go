@@ -0,0 +1,57 @@
+package examples
+
+import (
+	"testing"
+
+	"github.com/peterzeller/go-stateful-test/generator"
+	"github.com/peterzeller/go-stateful-test/generator/geniterable"
+	"github.com/peterzeller/go-stateful-test/pick"
+	"github.com/peterzeller/go-stateful-test/quickcheck"
+	"github.com/peterzeller/go-stateful-test/smallcheck"
+	"github.com/peterzeller/go-stateful-test/statefulTest"
+	"github.com/stretchr/testify/require"
+)
+
+// The generators used in these tests are generated by cmd/gen-generators (see shapes.go).
+
+func TestGeneratedDrawing(t *testing.T) {
+	quickcheck.Run(t, quickcheck.Config{}, func(t statefulTest.T) {
+		d := pick.Val(t, GenDrawing())
+		require.Nil(t, d.cache)
+		require.Contains(t, []Color{"red", "green", "blue"}, d.Color)
+		for _, s := range d.Shapes {
+			require.GreaterOrEqual(t, s.area(), 0)
+		}
+	})
+}
+
+func TestGeneratedExprEnumerate(t *testing.T) {
+	values := geniterable.ToSlice(generator.EnumerateValues(GenExpr(), 3))
+	require.Contains(t, values, Expr(Lit{Value: 1}))
+	require.Contains(t, values, Expr(Add{Left: Lit{Value: 0}, Right: Lit{Value: 1}}))
+}
+
+func TestGeneratedExprShrink(t *testing.T) {
+	log := expectError(t, func(t quickcheck.TestingT) {
+		quickcheck.Run(t, quickcheck.Config{Seed: 1}, func(t statefulTest.T) {
+			e := pick.Val(t, GenExpr())
+			if add, ok := e.(Add); ok && add.eval() > 100 {
+				t.Errorf("%v evaluates to %d", e, e.eval())
+			}
+		})
+	})
+	// depending on the seed, shrinking can stop at a nested expression, but the sum is always minimal
+	require.Regexp(t, `\) evaluates to 101\n`, log)
+}
+
+func TestGeneratedExprSmallCheck(t *testing.T) {
+	log := expectError(t, func(t quickcheck.TestingT) {
+		smallcheck.Run(t, smallcheck.Config{Depth: 4}, func(t statefulTest.T) {
+			e := pick.Val(t, GenExpr())
+			if e.eval() > 1 {
+				t.Errorf("%v evaluates to %d", e, e.eval())
+			}
+		})
+	})
+	require.Contains(t, log, "(1 + 1) evaluates to 2")
+}
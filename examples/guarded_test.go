@@ -0,0 +1,54 @@
+package examples
+
+import (
+	"testing"
+
+	"github.com/peterzeller/go-stateful-test/pick"
+	"github.com/peterzeller/go-stateful-test/quickcheck"
+	"github.com/peterzeller/go-stateful-test/smallcheck"
+	"github.com/peterzeller/go-stateful-test/statefulTest"
+	"github.com/stretchr/testify/require"
+)
+
+func guardedProperty(t statefulTest.T) {
+	open := false
+	for t.HasMore() {
+		pick.SwitchGuarded(t, pick.GuardedCases{
+			"open": {
+				Enabled: func() bool { return !open },
+				Run:     func() { open = true },
+			},
+			"close": {
+				Enabled: func() bool { return open },
+				Run:     func() { open = false },
+			},
+			"read": {
+				Enabled: func() bool { return open },
+				Run: func() {
+					require.True(t, open, "read on closed file")
+				},
+			},
+		})
+	}
+}
+
+func TestSwitchGuardedQuick(t *testing.T) {
+	quickcheck.Run(t, quickcheck.Config{}, guardedProperty)
+}
+
+func TestSwitchGuardedSmall(t *testing.T) {
+	smallcheck.Run(t, smallcheck.Config{}, guardedProperty)
+}
+
+func TestSwitchGuardedNoCaseEnabled(t *testing.T) {
+	log := expectError(t, func(t quickcheck.TestingT) {
+		quickcheck.Run(t, quickcheck.Config{}, func(t statefulTest.T) {
+			pick.SwitchWeighted(t, pick.WeightedCases{
+				"never": {Weight: 1, Enabled: func() bool { return false }, Run: func() {
+					t.Errorf("disabled case was chosen")
+				}},
+			})
+		})
+	})
+	require.Contains(t, log, "gave up after 0 passed")
+}
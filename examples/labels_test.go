@@ -0,0 +1,57 @@
+package examples
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/peterzeller/go-stateful-test/generator"
+	"github.com/peterzeller/go-stateful-test/pick"
+	"github.com/peterzeller/go-stateful-test/quickcheck"
+	"github.com/peterzeller/go-stateful-test/smallcheck"
+	"github.com/peterzeller/go-stateful-test/statefulTest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLabelsQuick(t *testing.T) {
+	quickcheck.Run(t, quickcheck.Config{}, func(t statefulTest.T) {
+		x := pick.Val(t, generator.IntRange(-10, 10))
+		t.Classify(x < 0, "negative")
+		t.Classify(x == 0, "zero")
+		t.Cover(20, x > 0, "positive")
+		t.Collect(len(pick.Val(t, generator.Slice(generator.Bool()))) > 0)
+	})
+}
+
+func TestCoverFailsQuick(t *testing.T) {
+	log := expectError(t, func(t quickcheck.TestingT) {
+		quickcheck.Run(t, quickcheck.Config{}, func(t statefulTest.T) {
+			x := pick.Val(t, generator.IntRange(0, 1000))
+			t.Cover(50, x == 0, "zero")
+		})
+	})
+	require.Contains(t, log, "Labels (100 test runs):\n")
+	require.Regexp(t, `Insufficient coverage: zero in \d+\.\d% of test runs, expected at least 50\.0%`, log)
+}
+
+func TestLabelsSmall(t *testing.T) {
+	log := expectError(t, func(t quickcheck.TestingT) {
+		smallcheck.Run(t, smallcheck.Config{}, func(t statefulTest.T) {
+			x := pick.Val(t, generator.Bool())
+			y := pick.Val(t, generator.Bool())
+			t.Label("run")
+			t.Classify(x && y, "both")
+			t.Cover(50, x || y, "any")
+			t.Cover(60, x == y, "equal")
+		})
+	})
+	// the log ends with the timing table of the depths
+	log = log[:strings.Index(log, "smallcheck depths:")]
+	require.Equal(t, "run is exhaustive with depth = 2\n"+
+		"Labels (4 test runs):\n"+
+		" 100.0% run\n"+
+		"  75.0% any (required: 50.0%)\n"+
+		"  50.0% equal (required: 60.0%)\n"+
+		"  25.0% both\n"+
+		"\n"+
+		"Insufficient coverage: equal in 50.0% of test runs, expected at least 60.0%\n", log)
+}
@@ -0,0 +1,101 @@
+package examples
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/peterzeller/go-stateful-test/quickcheck"
+	"github.com/peterzeller/go-stateful-test/statefulTest"
+	"github.com/peterzeller/go-stateful-test/statemachine"
+	"github.com/stretchr/testify/require"
+)
+
+// Counter is a shared counter.
+type Counter interface {
+	// Inc increments the counter and returns the new value
+	Inc() int64
+	Get() int64
+}
+
+// racyCounter reads and writes atomically, but the increment as a whole is not atomic.
+type racyCounter struct {
+	n int64
+}
+
+func (c *racyCounter) Inc() int64 {
+	v := atomic.LoadInt64(&c.n)
+	runtime.Gosched()
+	atomic.StoreInt64(&c.n, v+1)
+	return v + 1
+}
+
+func (c *racyCounter) Get() int64 {
+	return atomic.LoadInt64(&c.n)
+}
+
+type lockedCounter struct {
+	mu sync.Mutex
+	n  int64
+}
+
+func (c *lockedCounter) Inc() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.n++
+	return c.n
+}
+
+func (c *lockedCounter) Get() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.n
+}
+
+func counterSpec(newCounter func() Counter) statemachine.Spec[int64, Counter] {
+	return statemachine.Spec[int64, Counter]{
+		InitialModel: func() int64 {
+			return 0
+		},
+		NewSUT: func(t statefulTest.T) Counter {
+			return newCounter()
+		},
+		Commands: []statemachine.Command[int64, Counter]{
+			statemachine.Cmd[int64, Counter, struct{}, int64]{
+				Name: "inc",
+				Run: func(t statefulTest.T, c Counter, _ struct{}) int64 {
+					return c.Inc()
+				},
+				Next: func(m int64, _ struct{}) int64 {
+					return m + 1
+				},
+				Post: func(t statefulTest.T, m int64, _ struct{}, res int64) {
+					require.Equal(t, m+1, res)
+				},
+			},
+			statemachine.Cmd[int64, Counter, struct{}, int64]{
+				Name: "get",
+				Run: func(t statefulTest.T, c Counter, _ struct{}) int64 {
+					return c.Get()
+				},
+				Post: func(t statefulTest.T, m int64, _ struct{}, res int64) {
+					require.Equal(t, m, res)
+				},
+			},
+		},
+	}
+}
+
+func TestRacyCounterIsNotLinearizable(t *testing.T) {
+	out := expectError(t, func(t quickcheck.TestingT) {
+		statemachine.RunParallel(t, quickcheck.Config{}, statemachine.ParallelConfig{Repetitions: 100},
+			counterSpec(func() Counter { return &racyCounter{} }))
+	})
+	require.Contains(t, out, "History is not linearizable")
+}
+
+func TestLockedCounterIsLinearizable(t *testing.T) {
+	statemachine.RunParallel(t, quickcheck.Config{NumberOfRuns: 20}, statemachine.ParallelConfig{},
+		counterSpec(func() Counter { return &lockedCounter{} }))
+}
@@ -0,0 +1,52 @@
+package examples
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/peterzeller/go-stateful-test/generator"
+	"github.com/peterzeller/go-stateful-test/pick"
+	"github.com/peterzeller/go-stateful-test/quickcheck"
+	"github.com/peterzeller/go-stateful-test/smallcheck"
+	"github.com/peterzeller/go-stateful-test/statefulTest"
+	"github.com/stretchr/testify/require"
+)
+
+// checkSmall is a helper, so its messages show the location of the caller.
+func checkSmall(t statefulTest.T, x int) {
+	t.Helper()
+	t.Logf("checking x = %d", x)
+	require.Less(t, x, 3)
+}
+
+func TestLogLocation(t *testing.T) {
+	for _, run := range []func(t quickcheck.TestingT, f func(t statefulTest.T)){
+		func(t quickcheck.TestingT, f func(t statefulTest.T)) { quickcheck.Run(t, quickcheck.Config{}, f) },
+		func(t quickcheck.TestingT, f func(t statefulTest.T)) { smallcheck.Run(t, smallcheck.Config{}, f) },
+	} {
+		var line int
+		log := expectError(t, func(t quickcheck.TestingT) {
+			run(t, func(t statefulTest.T) {
+				x := pick.Val(t, generator.IntRange(0, 10))
+				line = callerLine() + 1
+				checkSmall(t, x)
+			})
+		})
+		require.Contains(t, log, fmt.Sprintf("location_test.go:%d: checking x = 3\n", line))
+		require.Contains(t, log, fmt.Sprintf("Error Trace:\t%s", thisFile()))
+		require.NotContains(t, log, "quickcheck/run.go")
+		require.NotContains(t, log, "smallcheck/state.go")
+	}
+}
+
+// callerLine returns the line of the call to callerLine
+func callerLine() int {
+	_, _, line, _ := runtime.Caller(1)
+	return line
+}
+
+func thisFile() string {
+	_, file, _, _ := runtime.Caller(0)
+	return file
+}
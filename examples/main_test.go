@@ -0,0 +1,23 @@
+package examples
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/peterzeller/go-stateful-test/quickcheck"
+)
+
+// TestMain stores the counterexamples of failing example tests in a temporary directory,
+// so that running the examples never adds entries to the failure DB in the source tree.
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "quickcheck-examples")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	quickcheck.DefaultFailureDBDir = dir
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
@@ -0,0 +1,104 @@
+package examples
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/peterzeller/go-stateful-test/generator"
+	"github.com/peterzeller/go-stateful-test/pick"
+	"github.com/peterzeller/go-stateful-test/quickcheck"
+	"github.com/peterzeller/go-stateful-test/smallcheck"
+	"github.com/peterzeller/go-stateful-test/statefulTest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParallelSameFailure(t *testing.T) {
+	prop := func(t statefulTest.T) {
+		x := pick.Val(t, generator.IntRange(0, 1000))
+		t.Assume(x%3 != 0)
+		// make later runs finish earlier, so that the workers complete the runs out of order
+		time.Sleep(time.Duration(1000-x) * time.Microsecond)
+		t.Logf("x = %d", x)
+		require.True(t, x < 700)
+	}
+	found := regexp.MustCompile(`Found error in run \d+ \(seed = \d+, size = \d+\)`)
+	shrunk := regexp.MustCompile(`x = \d+\n`)
+
+	sequential := expectError(t, func(t quickcheck.TestingT) {
+		quickcheck.Run(t, quickcheck.Config{Seed: 42}, prop)
+	})
+	parallel := expectError(t, func(t quickcheck.TestingT) {
+		quickcheck.Run(t, quickcheck.Config{Seed: 42, Parallelism: 8}, prop)
+	})
+	require.Equal(t, found.FindString(sequential), found.FindString(parallel))
+	require.Equal(t, shrunk.FindAllString(sequential, -1), shrunk.FindAllString(parallel, -1))
+}
+
+func TestParallelSameStats(t *testing.T) {
+	prop := func(t statefulTest.T) {
+		x := pick.Val(t, generator.IntRange(-10, 10))
+		t.Assume(x != 0)
+		t.Classify(x < 0, "negative")
+		t.Classify(x > 0, "positive")
+	}
+	sequential := &logT{}
+	quickcheck.Run(sequential, quickcheck.Config{Seed: 7}, prop)
+	parallel := &logT{}
+	quickcheck.Run(parallel, quickcheck.Config{Seed: 7, Parallelism: 4}, prop)
+
+	require.False(t, parallel.Failed())
+	require.Contains(t, parallel.log.String(), "Labels (100 test runs):\n")
+	require.Equal(t, sequential.log.String(), parallel.log.String())
+}
+
+func TestParallelSmallCheckSameFailure(t *testing.T) {
+	sequential := expectError(t, func(t quickcheck.TestingT) {
+		smallcheck.Run(t, smallcheck.Config{}, QueueProperty)
+	})
+	for _, splitDepth := range []int{1, 2, 4} {
+		parallel := expectError(t, func(t quickcheck.TestingT) {
+			smallcheck.Run(t, smallcheck.Config{Parallelism: 4, SplitDepth: splitDepth}, QueueProperty)
+		})
+		// the timing table at the end of the logs differs
+		require.Equal(t, withoutDepthTable(sequential), withoutDepthTable(parallel), "SplitDepth = %d", splitDepth)
+	}
+}
+
+func TestSmallCheckShards(t *testing.T) {
+	prop := func(t statefulTest.T) {
+		x := pick.Val(t, generator.IntRange(-3, 3))
+		y := pick.Val(t, generator.IntRange(-3, 3))
+		z := pick.Val(t, generator.Slice(generator.Bool()))
+		t.Classify(x < y, "x < y")
+		t.Collect(len(z))
+	}
+	sequential := &logT{}
+	smallcheck.Run(sequential, smallcheck.Config{Depth: 4}, prop)
+	require.False(t, sequential.Failed())
+
+	// every run is explored by exactly one shard
+	runs := regexp.MustCompile(`Labels \((\d+) test runs\)`)
+	total := 0
+	for shard := 0; shard < 3; shard++ {
+		l := &logT{}
+		smallcheck.Run(l, smallcheck.Config{Depth: 4, Parallelism: 2, ShardIndex: shard, ShardCount: 3}, prop)
+		require.False(t, l.Failed())
+		m := runs.FindStringSubmatch(l.log.String())
+		require.NotNil(t, m, l.log.String())
+		total += atoi(t, m[1])
+	}
+	require.Equal(t, runs.FindStringSubmatch(sequential.log.String())[1], strconv.Itoa(total))
+}
+
+func withoutDepthTable(log string) string {
+	return log[:strings.Index(log, "smallcheck depths:")]
+}
+
+func atoi(t *testing.T, s string) int {
+	i, err := strconv.Atoi(s)
+	require.NoError(t, err)
+	return i
+}
@@ -0,0 +1,71 @@
+package examples
+
+import (
+	"testing"
+
+	"github.com/peterzeller/go-stateful-test/generator"
+	"github.com/peterzeller/go-stateful-test/pick"
+	"github.com/peterzeller/go-stateful-test/quickcheck"
+	"github.com/peterzeller/go-stateful-test/smallcheck"
+	"github.com/peterzeller/go-stateful-test/statefulTest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExistsNegation(t *testing.T) {
+	smallcheck.Run(t, smallcheck.Config{}, func(t statefulTest.T) {
+		x := pick.Val(t, generator.Bool())
+		y, ok := smallcheck.ExistsUnique(t, generator.Bool(), func(y bool) bool {
+			return x != y
+		})
+		require.True(t, ok)
+		require.Equal(t, !x, y)
+	})
+}
+
+func TestExistsFails(t *testing.T) {
+	log := expectError(t, func(t quickcheck.TestingT) {
+		smallcheck.Run(t, smallcheck.Config{}, func(t statefulTest.T) {
+			x := pick.Val(t, generator.Int())
+			// negative numbers have no square root
+			smallcheck.Exists(t, generator.Int(), func(y int) bool {
+				return y*y == x
+			})
+		})
+	})
+	require.Regexp(t, `Exists: no inner value of \S+ found up to depth \d+ \(outer values: \[-1\]\)`, log)
+}
+
+func TestExistsUniqueFails(t *testing.T) {
+	log := expectError(t, func(t quickcheck.TestingT) {
+		smallcheck.Run(t, smallcheck.Config{}, func(t statefulTest.T) {
+			x := pick.Val(t, generator.IntRange(0, 4))
+			smallcheck.ExistsUnique(t, generator.IntRange(-2, 2), func(y int) bool {
+				return y*y == x
+			})
+		})
+	})
+	require.Regexp(t, `ExistsUnique: found several inner values of \S+: -?1 and -?1 \(outer values: \[1\]\)`, log)
+}
+
+func TestForAllFails(t *testing.T) {
+	log := expectError(t, func(t quickcheck.TestingT) {
+		smallcheck.Run(t, smallcheck.Config{}, func(t statefulTest.T) {
+			x := pick.Val(t, generator.Bool())
+			smallcheck.ForAll(t, generator.Bool(), func(y bool) bool {
+				return x || y
+			})
+		})
+	})
+	require.Contains(t, log, "ForAll: predicate does not hold for inner value false of OneConstantOf (outer values: [false])")
+}
+
+func TestQuantifierMarksRunNonExhaustive(t *testing.T) {
+	res := smallcheck.RunWithResult(t, smallcheck.Config{Depth: 3}, func(t statefulTest.T) {
+		x := pick.Val(t, generator.Bool())
+		smallcheck.ForAll(t, generator.Int(), func(y int) bool {
+			return x || y*y >= 0
+		})
+	})
+	require.False(t, res.Exhaustive)
+	require.Contains(t, res.Depths[len(res.Depths)-1].NonExhaustive, generator.Int().Name())
+}
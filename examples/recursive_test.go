@@ -0,0 +1,89 @@
+package examples
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/peterzeller/go-stateful-test/generator"
+	"github.com/peterzeller/go-stateful-test/pick"
+	"github.com/peterzeller/go-stateful-test/quickcheck"
+	"github.com/peterzeller/go-stateful-test/smallcheck"
+	"github.com/peterzeller/go-stateful-test/statefulTest"
+	"github.com/stretchr/testify/require"
+)
+
+// expr is a simple arithmetic expression: either a constant or an operation with two arguments.
+type expr struct {
+	op          string
+	left, right *expr
+	value       int
+}
+
+func (e *expr) String() string {
+	if e.op == "" {
+		return fmt.Sprintf("%d", e.value)
+	}
+	return fmt.Sprintf("(%v %s %v)", e.left, e.op, e.right)
+}
+
+func (e *expr) eval() int {
+	switch e.op {
+	case "+":
+		return e.left.eval() + e.right.eval()
+	case "*":
+		return e.left.eval() * e.right.eval()
+	default:
+		return e.value
+	}
+}
+
+// optimize simplifies expressions, but has a bug: x * 1 is simplified to 1 instead of x
+func optimize(e *expr) *expr {
+	if e.op == "" {
+		return e
+	}
+	l, r := optimize(e.left), optimize(e.right)
+	if e.op == "*" && r.op == "" && r.value == 1 {
+		return r
+	}
+	if e.op == "+" && r.op == "" && r.value == 0 {
+		return l
+	}
+	return &expr{op: e.op, left: l, right: r}
+}
+
+func genExpr() generator.Generator[*expr, interface{}] {
+	return generator.Recursive(func(self generator.Generator[*expr, interface{}]) generator.Generator[*expr, interface{}] {
+		return generator.UntypedR(generator.OneOf(
+			generator.UntypedR(generator.Map(generator.IntRange(0, 5), func(v int) *expr {
+				return &expr{value: v}
+			})),
+			generator.UntypedR(generator.Zip(self, self, func(l, r *expr) *expr {
+				return &expr{op: "+", left: l, right: r}
+			})),
+			generator.UntypedR(generator.Zip(self, self, func(l, r *expr) *expr {
+				return &expr{op: "*", left: l, right: r}
+			}))))
+	})
+}
+
+func optimizeProperty(t statefulTest.T) {
+	e := pick.Val(t, genExpr())
+	t.Logf("e = %v", e)
+	require.Equal(t, e.eval(), optimize(e).eval())
+}
+
+func TestRecursiveQuick(t *testing.T) {
+	log := expectError(t, func(t quickcheck.TestingT) {
+		quickcheck.Run(t, quickcheck.Config{NumberOfRuns: 1000}, optimizeProperty)
+	})
+	// the expression is shrunk to one of its failing subexpressions
+	require.Regexp(t, `e = \([02-5] \* 1\)\n`, log)
+}
+
+func TestRecursiveSmall(t *testing.T) {
+	log := expectError(t, func(t quickcheck.TestingT) {
+		smallcheck.Run(t, smallcheck.Config{}, optimizeProperty)
+	})
+	require.Contains(t, log, "e = (0 * 1)\n")
+}
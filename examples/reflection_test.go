@@ -0,0 +1,53 @@
+package examples
+
+import (
+	"testing"
+	"unicode/utf8"
+
+	"github.com/peterzeller/go-stateful-test/generator"
+	"github.com/peterzeller/go-stateful-test/pick"
+	"github.com/peterzeller/go-stateful-test/quickcheck"
+	"github.com/peterzeller/go-stateful-test/smallcheck"
+	"github.com/peterzeller/go-stateful-test/statefulTest"
+	"github.com/stretchr/testify/require"
+)
+
+type Profile struct {
+	Name    string           `gen:"minlen=5,maxlen=10"`
+	Initial string           `gen:"chars=A-Z,nonzero,maxlen=1"`
+	Age     int8             `gen:"nonzero"`
+	Score   float64          `gen:"min=0,max=1,nonzero"`
+	Tags    []bool           `gen:"minlen=2"`
+	Limits  map[uint8]bool   `gen:"minlen=3,maxlen=3"`
+	Admin   bool             `gen:"nonzero"`
+	Manager *Profile         `gen:"-"`
+	Parent  *struct{ X int } `gen:"nonzero"`
+}
+
+func checkProfile(t statefulTest.T, p Profile) {
+	require.True(t, 5 <= utf8.RuneCountInString(p.Name) && utf8.RuneCountInString(p.Name) <= 10, "%+v", p)
+	require.Regexp(t, "^[A-Z]$", p.Initial)
+	require.NotZero(t, p.Age)
+	require.True(t, 0 < p.Score && p.Score <= 1, "%+v", p)
+	require.GreaterOrEqual(t, len(p.Tags), 2)
+	require.Len(t, p.Limits, 3)
+	require.True(t, p.Admin)
+	require.NotNil(t, p.Parent)
+}
+
+// the struct tags are satisfied for all sizes, including size 0 in the first quickcheck run
+func TestReflectionTagsQuick(t *testing.T) {
+	g := generator.ReflectionGen[Profile](generator.ReflectionGenDefaultOpts())
+	quickcheck.Run(t, quickcheck.Config{}, func(t statefulTest.T) {
+		checkProfile(t, pick.Val(t, g))
+	})
+}
+
+func TestReflectionTagsSmall(t *testing.T) {
+	g := generator.ReflectionGen[Profile](generator.ReflectionGenDefaultOpts())
+	res := smallcheck.RunWithResult(t, smallcheck.Config{Depth: 4, MaxRuns: 1000}, func(t statefulTest.T) {
+		checkProfile(t, pick.Val(t, g))
+	})
+	// small depths do not have enough distinct map keys
+	require.Positive(t, res.Depths[len(res.Depths)-1].Passed)
+}
@@ -0,0 +1,149 @@
+package examples
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/peterzeller/go-stateful-test/generator"
+	"github.com/peterzeller/go-stateful-test/pick"
+	"github.com/peterzeller/go-stateful-test/quickcheck"
+	"github.com/peterzeller/go-stateful-test/smallcheck"
+	"github.com/peterzeller/go-stateful-test/statefulTest"
+	"github.com/stretchr/testify/require"
+)
+
+// TestQueueReproducer was generated by smallcheck with Config.Reproducer for QueueProperty.
+func TestQueueReproducer(t *testing.T) {
+	log := expectError(t, func(t quickcheck.TestingT) {
+		pick.Replay(t, QueueProperty,
+			pick.V(1),
+			pick.More(true),
+			pick.V("put"),
+			pick.V(0),
+			pick.More(true),
+			pick.V("get"),
+			pick.More(true),
+			pick.V("put"),
+			pick.V(0),
+		)
+	})
+	require.Contains(t, log, "Calling q.Put(0)\nCalling q.Get() -> 0\nCalling q.Put(0)\n")
+	require.Contains(t, log, "invariant: queue size")
+}
+
+func TestReplayConvertsValues(t *testing.T) {
+	l := &logT{}
+	pick.Replay(l, func(t statefulTest.T) {
+		x := pick.Val(t, generator.Int64())
+		u := pick.Val(t, generator.UInt8())
+		f := pick.Val(t, generator.Float64())
+		b := pick.Val(t, generator.UInt64())
+		require.Equal(t, int64(3), x)
+		require.Equal(t, uint8(4), u)
+		require.Equal(t, 2.0, f)
+		require.Equal(t, uint64(math.MaxUint64), b)
+	}, pick.V(3), pick.V(uint8(4)), pick.V(2), pick.V(uint64(18446744073709551615)))
+	require.False(t, l.Failed(), l.log.String())
+}
+
+func TestReplayUsesStaticType(t *testing.T) {
+	// the value is converted without drawing a sample from the generator
+	gen := &generator.AnonGenerator[uint8, uint8]{
+		GenName: "noRandom",
+		GenRandom: func(rnd generator.Rand, size int) uint8 {
+			panic("Random must not be called when replaying")
+		},
+		GenRValue: func(r uint8) (uint8, bool) {
+			return r, true
+		},
+	}
+	l := &logT{}
+	pick.Replay(l, func(t statefulTest.T) {
+		x := pick.Val[uint8, uint8](t, gen)
+		e := pick.Val(t, GenExpr())
+		require.Equal(t, uint8(7), x)
+		require.Equal(t, Expr(Lit{Value: 1}), e)
+	}, pick.V(7), pick.V(Lit{Value: 1}))
+	require.False(t, l.Failed(), l.log.String())
+}
+
+func TestReplayMismatch(t *testing.T) {
+	log := expectError(t, func(t quickcheck.TestingT) {
+		pick.Replay(t, func(t statefulTest.T) {
+			for t.HasMore() {
+				pick.Val(t, generator.Int())
+			}
+		}, pick.More(true), pick.V(1))
+	})
+	require.Contains(t, log, "pick.Replay: test run uses more than the 2 recorded steps (HasMore)")
+
+	log = expectError(t, func(t quickcheck.TestingT) {
+		pick.Replay(t, func(t statefulTest.T) {
+			pick.Val(t, generator.Int())
+		}, pick.V(1), pick.V(2))
+	})
+	require.Contains(t, log, "pick.Replay: test run ended after 1 of 2 steps")
+}
+
+func TestQuickCheckReproducerFile(t *testing.T) {
+	dir := t.TempDir()
+	res := runNamed("TestMax3", func(t quickcheck.TestingT) {
+		quickcheck.Run(t, quickcheck.Config{Seed: 1, NumberOfRuns: 1000, FailureDBDir: dir, Reproducer: true}, func(t statefulTest.T) {
+			x := pick.Val(t, generator.Int())
+			y := pick.Val(t, generator.Int())
+			z := pick.Val(t, generator.Int())
+			require.True(t, max3(x, y, z) >= x)
+		})
+	})
+	require.True(t, res.Failed())
+	path := filepath.Join(dir, "TestMax3_reproducer.go")
+	require.Contains(t, res.log.String(), "Reproducer stored in "+path)
+	src, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Regexp(t, `func TestMax3Reproducer\(t \*testing.T\) \{
+	pick.Replay\(t, property,
+		pick.V\(-?\d+\),
+		pick.V\(-?\d+\),
+		pick.V\(-?\d+\),
+	\)
+\}`, string(src))
+}
+
+func TestQuickCheckReproducerFallbackFromDB(t *testing.T) {
+	type point struct{ x, y int }
+	dir := t.TempDir()
+	prop := func(t statefulTest.T) {
+		p := pick.Val(t, generator.Map(generator.IntRange(0, 100), func(x int) point { return point{x, x} }))
+		require.Less(t, p.x, 50)
+	}
+	first := runNamed("TestPoint", func(t quickcheck.TestingT) {
+		quickcheck.Run(t, quickcheck.Config{Seed: 1, FailureDBDir: dir}, prop)
+	})
+	require.True(t, first.Failed())
+	stored, err := quickcheck.FailureDB{Dir: dir}.Load("TestPoint")
+	require.NoError(t, err)
+	require.Len(t, stored, 1)
+
+	// the failure is replayed from the failure DB, so the fallback uses the stored seed instead of the base seed
+	second := runNamed("TestPoint", func(t quickcheck.TestingT) {
+		quickcheck.Run(t, quickcheck.Config{Seed: 7, FailureDBDir: dir, Reproducer: true}, prop)
+	})
+	require.True(t, second.Failed())
+	require.Contains(t, second.log.String(), fmt.Sprintf("\tquickcheck.Run(t, quickcheck.Config{Seed: %d, MinSize: %d, MaxSize: %d}, property)\n",
+		stored[0].Seed, stored[0].Size, stored[0].Size))
+}
+
+func TestSmallCheckReproducerFallback(t *testing.T) {
+	type point struct{ x, y int }
+	log := expectError(t, func(t quickcheck.TestingT) {
+		smallcheck.Run(t, smallcheck.Config{Reproducer: true}, func(t statefulTest.T) {
+			p := pick.Val(t, generator.Map(generator.Int(), func(x int) point { return point{x, x} }))
+			require.Equal(t, 0, p.x)
+		})
+	})
+	require.Contains(t, log, "// the picked values cannot be written as Go literals, so the test replays the seed\n"+
+		"\tsmallcheck.Run(t, smallcheck.Config{Depth: 5}, property)\n")
+}
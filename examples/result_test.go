@@ -0,0 +1,67 @@
+package examples
+
+import (
+	"testing"
+
+	"github.com/peterzeller/go-stateful-test/generator"
+	"github.com/peterzeller/go-stateful-test/pick"
+	"github.com/peterzeller/go-stateful-test/quickcheck"
+	"github.com/peterzeller/go-stateful-test/smallcheck"
+	"github.com/peterzeller/go-stateful-test/statefulTest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunWithResultExhaustive(t *testing.T) {
+	res := smallcheck.RunWithResult(t, smallcheck.Config{}, func(t statefulTest.T) {
+		x := pick.Val(t, generator.Bool())
+		y := pick.Val(t, generator.Bool())
+		t.Assume(x || y)
+	})
+	require.False(t, res.Failed)
+	require.True(t, res.Exhaustive)
+	last := res.Depths[len(res.Depths)-1]
+	require.Equal(t, 2, last.Depth)
+	require.Equal(t, 4, last.Runs)
+	require.Equal(t, 3, last.Passed)
+	require.Equal(t, 1, last.Discarded)
+	require.True(t, last.Complete)
+	require.Empty(t, last.NonExhaustive)
+}
+
+func TestRunWithResultNonExhaustive(t *testing.T) {
+	res := smallcheck.RunWithResult(t, smallcheck.Config{Depth: 5}, sumProperty)
+	require.False(t, res.Failed)
+	require.False(t, res.Exhaustive)
+	require.Len(t, res.Depths, 4)
+	for i, d := range res.Depths {
+		require.Equal(t, i+1, d.Depth)
+		require.Contains(t, d.NonExhaustive, "HasMore")
+		require.False(t, d.Exhaustive)
+		if i > 0 {
+			require.Greater(t, d.Runs, res.Depths[i-1].Runs)
+		}
+	}
+	require.Equal(t, res.Depths[0].Runs+res.Depths[1].Runs+res.Depths[2].Runs+res.Depths[3].Runs, res.Runs())
+}
+
+func TestRunWithResultEmptyIterators(t *testing.T) {
+	res := smallcheck.RunWithResult(t, smallcheck.Config{Depth: 3}, func(t statefulTest.T) {
+		if pick.Val(t, generator.Bool()) {
+			pick.Val(t, generator.Empty[int, int]())
+		}
+	})
+	require.False(t, res.Failed)
+	last := res.Depths[len(res.Depths)-1]
+	require.Equal(t, 2, last.Runs)
+	require.Equal(t, 1, last.Passed)
+	require.Equal(t, 1, last.EmptyIterators)
+}
+
+func TestRunWithResultFailure(t *testing.T) {
+	var res smallcheck.Result
+	expectError(t, func(t quickcheck.TestingT) {
+		res = smallcheck.RunWithResult(t, smallcheck.Config{}, QueueProperty)
+	})
+	require.True(t, res.Failed)
+	require.False(t, res.Depths[len(res.Depths)-1].Complete)
+}
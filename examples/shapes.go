@@ -0,0 +1,84 @@
+package examples
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/peterzeller/go-stateful-test/generator"
+)
+
+//go:generate go run ../cmd/gen-generators -type=Drawing,Expr -output=shapes_generators.go
+
+// Shape is a sum type implemented by Circle and Rect.
+type Shape interface {
+	area() int
+}
+
+type Circle struct {
+	Radius uint8
+}
+
+func (c Circle) area() int {
+	return 3 * int(c.Radius) * int(c.Radius)
+}
+
+type Rect struct {
+	Width, Height uint8
+}
+
+func (r *Rect) area() int {
+	return int(r.Width) * int(r.Height)
+}
+
+type Point [2]int8
+
+// Color defines its own generator, which is used by the generated generators.
+type Color string
+
+func (Color) Generator() generator.Generator[Color, interface{}] {
+	return generator.UntypedR(generator.OneConstantOf[Color]("red", "green", "blue"))
+}
+
+type Style struct {
+	Color   Color
+	Opacity *float32
+}
+
+// Drawing uses all kinds of types supported by gen-generators.
+type Drawing struct {
+	Style
+	Shapes  []Shape
+	Origin  Point
+	Layers  map[string]bool
+	Created time.Duration
+	cache   []byte `gen:"-"`
+}
+
+// Expr is a recursive sum type.
+type Expr interface {
+	eval() int
+}
+
+type Lit struct {
+	Value int8
+}
+
+func (l Lit) eval() int {
+	return int(l.Value)
+}
+
+type Add struct {
+	Left, Right Expr
+}
+
+func (a Add) eval() int {
+	return a.Left.eval() + a.Right.eval()
+}
+
+func (a Add) String() string {
+	return fmt.Sprintf("(%v + %v)", a.Left, a.Right)
+}
+
+func (l Lit) String() string {
+	return fmt.Sprintf("%d", l.Value)
+}
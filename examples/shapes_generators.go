@@ -0,0 +1,135 @@
+// Code generated by gen-generators; DO NOT EDIT.
+
+package examples
+
+import (
+	"time"
+
+	"github.com/peterzeller/go-stateful-test/generator"
+)
+
+// GenDrawing returns a generator for Drawing.
+func GenDrawing() generator.Generator[Drawing, interface{}] {
+	g0 := generator.Map(GenStyle(), func(v Style) Drawing {
+		var s Drawing
+		s.Style = v
+		return s
+	})
+	g1 := generator.Zip(g0, generator.Slice(GenShape()), func(s Drawing, v []Shape) Drawing {
+		s.Shapes = v
+		return s
+	})
+	g2 := generator.Zip(g1, GenPoint(), func(s Drawing, v Point) Drawing {
+		s.Origin = v
+		return s
+	})
+	g3 := generator.Zip(g2, generator.DictMut(generator.String(), generator.Bool()), func(s Drawing, v map[string]bool) Drawing {
+		s.Layers = v
+		return s
+	})
+	g4 := generator.Zip(g3, generator.Any[time.Duration](), func(s Drawing, v time.Duration) Drawing {
+		s.Created = v
+		return s
+	})
+	return generator.UntypedR(g4)
+}
+
+// GenExpr returns a generator for Expr.
+func GenExpr() generator.Generator[Expr, interface{}] {
+	return generator.Recursive(func(selfExpr generator.Generator[Expr, interface{}]) generator.Generator[Expr, interface{}] {
+		return generator.UntypedR(generator.OneOf(
+			generator.UntypedR(generator.Map(GenLit(), func(v Lit) Expr {
+				return v
+			})),
+			generator.UntypedR(generator.Map(func() generator.Generator[Add, interface{}] {
+				g0 := generator.Map(selfExpr, func(v Expr) Add {
+					var s Add
+					s.Left = v
+					return s
+				})
+				g1 := generator.Zip(g0, selfExpr, func(s Add, v Expr) Add {
+					s.Right = v
+					return s
+				})
+				return generator.UntypedR(g1)
+			}(), func(v Add) Expr {
+				return v
+			})),
+		))
+	})
+}
+
+// GenStyle returns a generator for Style.
+func GenStyle() generator.Generator[Style, interface{}] {
+	g0 := generator.Map(generator.Any[Color](), func(v Color) Style {
+		var s Style
+		s.Color = v
+		return s
+	})
+	g1 := generator.Zip(g0, generator.Frequency(
+		generator.Weighted(1, generator.UntypedR(generator.Constant[*float32](nil))),
+		generator.Weighted(3, generator.UntypedR(generator.Map(generator.Float32(), func(v float32) *float32 {
+			return &v
+		})))), func(s Style, v *float32) Style {
+		s.Opacity = v
+		return s
+	})
+	return generator.UntypedR(g1)
+}
+
+// GenShape returns a generator for Shape.
+func GenShape() generator.Generator[Shape, interface{}] {
+	return generator.UntypedR(generator.OneOf(
+		generator.UntypedR(generator.Map(GenCircle(), func(v Circle) Shape {
+			return v
+		})),
+		generator.UntypedR(generator.Map(GenRect(), func(v Rect) Shape {
+			return &v
+		})),
+	))
+}
+
+// GenPoint returns a generator for Point.
+func GenPoint() generator.Generator[Point, interface{}] {
+	return generator.UntypedR(generator.Map(generator.Map(generator.SliceFixedLength(generator.Int8(), 2), func(s []int8) [2]int8 {
+		var a [2]int8
+		copy(a[:], s)
+		return a
+	}), func(v [2]int8) Point {
+		return Point(v)
+	}))
+}
+
+// GenLit returns a generator for Lit.
+func GenLit() generator.Generator[Lit, interface{}] {
+	g0 := generator.Map(generator.Int8(), func(v int8) Lit {
+		var s Lit
+		s.Value = v
+		return s
+	})
+	return generator.UntypedR(g0)
+}
+
+// GenCircle returns a generator for Circle.
+func GenCircle() generator.Generator[Circle, interface{}] {
+	g0 := generator.Map(generator.UInt8(), func(v uint8) Circle {
+		var s Circle
+		s.Radius = v
+		return s
+	})
+	return generator.UntypedR(g0)
+}
+
+// GenRect returns a generator for Rect.
+func GenRect() generator.Generator[Rect, interface{}] {
+	g0 := generator.Map(generator.UInt8(), func(v uint8) Rect {
+		var s Rect
+		s.Width = v
+		return s
+	})
+	g1 := generator.Zip(g0, generator.UInt8(), func(s Rect, v uint8) Rect {
+		s.Height = v
+		return s
+	})
+	return generator.UntypedR(g1)
+}
@@ -3,6 +3,7 @@ package examples
 import (
 	"github.com/peterzeller/go-stateful-test/generator"
 	"github.com/peterzeller/go-stateful-test/pick"
+	"github.com/peterzeller/go-stateful-test/quickcheck"
 	"github.com/peterzeller/go-stateful-test/smallcheck"
 	"github.com/peterzeller/go-stateful-test/statefulTest"
 	"github.com/stretchr/testify/require"
@@ -55,3 +56,19 @@ func TestSmallExhaustiveStop(t *testing.T) {
 	}
 	require.Equal(t, 1, lastValueFound, "the last value should only be found once, due to the exhaustiveness check")
 }
+
+// TestHasMoreCutoffExploresLargerDepths tests that a HasMore loop cut off at the current depth is explored further.
+func TestHasMoreCutoffExploresLargerDepths(t *testing.T) {
+	// the loop does not pick any values, so only the HasMore cutoff makes the depth relevant
+	log := expectError(t, func(t quickcheck.TestingT) {
+		smallcheck.Run(t, smallcheck.Config{Depth: 5}, func(t statefulTest.T) {
+			n := 0
+			for t.HasMore() {
+				n++
+			}
+			t.Logf("n = %d", n)
+			require.Less(t, n, 3)
+		})
+	})
+	require.Contains(t, log, "n = 3\n")
+}
@@ -0,0 +1,87 @@
+package examples
+
+import (
+	"testing"
+
+	"github.com/peterzeller/go-stateful-test/generator"
+	"github.com/peterzeller/go-stateful-test/quickcheck"
+	"github.com/peterzeller/go-stateful-test/smallcheck"
+	"github.com/peterzeller/go-stateful-test/statefulTest"
+	"github.com/peterzeller/go-stateful-test/statemachine"
+	"github.com/stretchr/testify/require"
+)
+
+// queueModel is the model for the Queue from example_statemachine_test.go
+type queueModel struct {
+	capacity int
+	elems    []int
+}
+
+type queueSUT struct {
+	q *Queue
+}
+
+// queueSpec describes the queue as a state machine.
+// It tests the same properties as QueueProperty, but the preconditions are declared on the commands.
+func queueSpec(capacity int) statemachine.Spec[queueModel, queueSUT] {
+	return statemachine.Spec[queueModel, queueSUT]{
+		InitialModel: func() queueModel {
+			return queueModel{capacity: capacity}
+		},
+		NewSUT: func(t statefulTest.T) queueSUT {
+			return queueSUT{q: NewQueue(capacity)}
+		},
+		Commands: []statemachine.Command[queueModel, queueSUT]{
+			statemachine.Cmd[queueModel, queueSUT, int, struct{}]{
+				Name: "put",
+				Pre: func(m queueModel) bool {
+					return len(m.elems) < m.capacity
+				},
+				Args: func(m queueModel) generator.Generator[int, interface{}] {
+					return generator.UntypedR(generator.Int())
+				},
+				Run: func(t statefulTest.T, sut queueSUT, i int) struct{} {
+					sut.q.Put(i)
+					return struct{}{}
+				},
+				Next: func(m queueModel, i int) queueModel {
+					// copy the slice, because the old model must not be modified
+					elems := append(append([]int{}, m.elems...), i)
+					return queueModel{capacity: m.capacity, elems: elems}
+				},
+			},
+			statemachine.Cmd[queueModel, queueSUT, struct{}, int]{
+				Name: "get",
+				Pre: func(m queueModel) bool {
+					return len(m.elems) > 0
+				},
+				Run: func(t statefulTest.T, sut queueSUT, _ struct{}) int {
+					return sut.q.Get()
+				},
+				Next: func(m queueModel, _ struct{}) queueModel {
+					return queueModel{capacity: m.capacity, elems: m.elems[1:]}
+				},
+				Post: func(t statefulTest.T, m queueModel, _ struct{}, res int) {
+					require.Equal(t, m.elems[0], res, "result of q.Get()")
+				},
+			},
+		},
+		Invariant: func(t statefulTest.T, m queueModel, sut queueSUT) {
+			require.Equal(t, len(m.elems), sut.q.Size(), "invariant: queue size")
+		},
+	}
+}
+
+func TestQueueStateMachineQuickCheck(t *testing.T) {
+	out := expectError(t, func(t quickcheck.TestingT) {
+		statemachine.Run(t, quickcheck.Config{}, queueSpec(2))
+	})
+	// the minimal sequence needs 4 commands, so that the index wraps around
+	require.Regexp(t, `Command sequence:\n  0: put\(0\)\n  1: (put\(0\)|get\(\))\n  2: (put\(0\)|get\(\))\n  3: put\(0\)\n\n`, out)
+}
+
+func TestQueueStateMachineSmallCheck(t *testing.T) {
+	expectError(t, func(t quickcheck.TestingT) {
+		smallcheck.Run(t, smallcheck.Config{}, queueSpec(2).Property())
+	})
+}
@@ -0,0 +1,77 @@
+package examples
+
+import (
+	"testing"
+
+	"github.com/peterzeller/go-stateful-test/generator"
+	"github.com/peterzeller/go-stateful-test/pick"
+	"github.com/peterzeller/go-stateful-test/quickcheck"
+	"github.com/peterzeller/go-stateful-test/smallcheck"
+	"github.com/peterzeller/go-stateful-test/statefulTest"
+	"github.com/stretchr/testify/require"
+)
+
+// compactingLog stores integers and can be compacted, which has a bug for logs with a single entry.
+type compactingLog struct {
+	entries []int
+}
+
+func (l *compactingLog) Append(i int) {
+	l.entries = append(l.entries, i)
+}
+
+func (l *compactingLog) Compact() {
+	if len(l.entries) == 1 {
+		l.entries = nil
+	}
+}
+
+func compactingLogProperty(t statefulTest.T) {
+	l := &compactingLog{}
+	var model []int
+	for t.HasMore() {
+		pick.SwitchWeighted(t, pick.WeightedCases{
+			"append": {Weight: 10, Run: func() {
+				i := pick.Val(t, generator.IntRange(0, 9))
+				t.Logf("Append(%d)", i)
+				l.Append(i)
+				model = append(model, i)
+			}},
+			"compact": {Weight: 1, Run: func() {
+				t.Logf("Compact()")
+				l.Compact()
+			}},
+		})
+		require.Equal(t, model, l.entries)
+	}
+}
+
+func TestSwitchWeightedQuick(t *testing.T) {
+	log := expectError(t, func(t quickcheck.TestingT) {
+		quickcheck.Run(t, quickcheck.Config{}, compactingLogProperty)
+	})
+	require.Regexp(t, `weighted_test.go:\d+: Append\(0\)\nweighted_test.go:\d+: Compact\(\)\n`, log)
+}
+
+func TestSwitchWeightedSmall(t *testing.T) {
+	log := expectError(t, func(t quickcheck.TestingT) {
+		smallcheck.Run(t, smallcheck.Config{}, compactingLogProperty)
+	})
+	require.Regexp(t, `weighted_test.go:\d+: Append\(0\)\nweighted_test.go:\d+: Compact\(\)\n`, log)
+}
+
+func TestSwitchWeightedDistribution(t *testing.T) {
+	quickcheck.Run(t, quickcheck.Config{NumberOfRuns: 1000}, func(t statefulTest.T) {
+		pick.SwitchWeighted(t, pick.WeightedCases{
+			"common": {Weight: 9, Run: func() {
+				t.Cover(80, true, "common")
+			}},
+			"rare": {Weight: 1, Run: func() {
+				t.Cover(5, true, "rare")
+			}},
+			"never": {Weight: 0, Run: func() {
+				t.Errorf("case with weight 0 was chosen")
+			}},
+		})
+	})
+}
@@ -0,0 +1,176 @@
+package generator
+
+import (
+	"math/big"
+	"reflect"
+
+	"github.com/peterzeller/go-fun/iterable"
+	"github.com/peterzeller/go-fun/zero"
+	"github.com/peterzeller/go-stateful-test/generator/geniterable"
+)
+
+// Arbitrary is implemented by types that define their default generator.
+// The method is called on the zero value of the type, so it should not depend on the receiver.
+//
+// Any and ReflectionGen use this generator for the type, including for elements of slices, maps, and struct fields.
+// They detect the method by its name and signature, so a Generator method may also return a Generator[T, R]
+// with a typed representation R instead of interface{}.
+type Arbitrary[T any] interface {
+	Generator() Generator[T, interface{}]
+}
+
+// Any returns the default generator for type T.
+// This is the generator defined by T if it has a Generator method (see Arbitrary), and otherwise ReflectionGen with the default options.
+func Any[T any]() Generator[T, interface{}] {
+	if a, ok := interface{}(zero.Value[T]()).(Arbitrary[T]); ok {
+		return a.Generator()
+	}
+	return ReflectionGen[T](ReflectionGenDefaultOpts())
+}
+
+// rGenerator contains the methods of Generator[T, interface{}] that do not depend on T.
+type rGenerator interface {
+	Name() string
+	Random(rnd Rand, size int) interface{}
+	Shrink(elem interface{}) iterable.Iterable[interface{}]
+	Size(elem interface{}) *big.Int
+	Enumerate(depth int) geniterable.Iterable[interface{}]
+}
+
+// arbitraryGenerator returns the generator of a type implementing Arbitrary.
+// Since T is only known at runtime, RValue is called using reflection.
+func arbitraryGenerator(t reflect.Type) (UntypedGenerator, bool) {
+	m, ok := arbitraryMethod(t)
+	if !ok {
+		return nil, false
+	}
+	genValue := reflect.Zero(t).Method(m.Index).Call(nil)[0]
+	g, ok := genValue.Interface().(rGenerator)
+	if !ok {
+		return typedArbitraryGenerator(t, genValue), true
+	}
+	rValueMethod := genValue.MethodByName("RValue")
+	wrapR := func(e interface{}) UR {
+		return UR{e}
+	}
+	return untypedGen{
+		name: g.Name,
+		random: func(rnd Rand, size int) UR {
+			return UR{g.Random(rnd, size)}
+		},
+		enumerate: func(depth int) geniterable.Iterable[UR] {
+			return geniterable.Map(g.Enumerate(depth), wrapR)
+		},
+		shrink: func(elem UR) iterable.Iterable[UR] {
+			return iterable.Map(g.Shrink(elem.value), wrapR)
+		},
+		rvalue: func(elem UR) (UV, bool) {
+			arg := reflect.ValueOf(&elem.value).Elem()
+			results := rValueMethod.Call([]reflect.Value{arg})
+			return UV{results[0].Interface()}, results[1].Bool()
+		},
+		size: func(elem UR) *big.Int {
+			return g.Size(elem.value)
+		},
+		valueType: t,
+	}, true
+}
+
+// typedArbitraryGenerator returns the generator for a Generator method with a typed representation.
+// The representation type R is only known at runtime, so all methods are called using reflection.
+func typedArbitraryGenerator(t reflect.Type, genValue reflect.Value) UntypedGenerator {
+	call := func(name string, args ...interface{}) []reflect.Value {
+		in := make([]reflect.Value, len(args))
+		for i, arg := range args {
+			in[i] = reflect.ValueOf(arg)
+		}
+		return genValue.MethodByName(name).Call(in)
+	}
+	rType := genValue.MethodByName("Random").Type().Out(0)
+	// rArg converts a representation value back to R, which is nil if R is an interface type
+	rArg := func(elem UR) reflect.Value {
+		if elem.value == nil {
+			return reflect.Zero(rType)
+		}
+		return reflect.ValueOf(elem.value)
+	}
+	return untypedGen{
+		name: func() string {
+			return call("Name")[0].String()
+		},
+		random: func(rnd Rand, size int) UR {
+			in := []reflect.Value{reflect.ValueOf(&rnd).Elem(), reflect.ValueOf(size)}
+			return UR{genValue.MethodByName("Random").Call(in)[0].Interface()}
+		},
+		enumerate: func(depth int) geniterable.Iterable[UR] {
+			values := call("Enumerate", depth)[0]
+			return geniterable.IterableFun[UR](func() geniterable.Iterator[UR] {
+				next := values.MethodByName("Iterator").Call(nil)[0].MethodByName("Next")
+				return geniterable.Fun[UR](func() geniterable.NextResult[UR] {
+					res := next.Call(nil)[0]
+					if res.MethodByName("Present").Call(nil)[0].Bool() {
+						return geniterable.ResultSome(UR{res.MethodByName("Value").Call(nil)[0].Interface()})
+					}
+					return geniterable.ResultNone[UR](res.MethodByName("Exhaustive").Call(nil)[0].Bool())
+				})
+			})
+		},
+		shrink: func(elem UR) iterable.Iterable[UR] {
+			shrinks := genValue.MethodByName("Shrink").Call([]reflect.Value{rArg(elem)})[0]
+			return iterable.IterableFun[UR](func() iterable.Iterator[UR] {
+				next := shrinks.MethodByName("Iterator").Call(nil)[0].MethodByName("Next")
+				return iterable.Fun[UR](func() (UR, bool) {
+					res := next.Call(nil)
+					return UR{res[0].Interface()}, res[1].Bool()
+				})
+			})
+		},
+		rvalue: func(elem UR) (UV, bool) {
+			results := genValue.MethodByName("RValue").Call([]reflect.Value{rArg(elem)})
+			return UV{results[0].Interface()}, results[1].Bool()
+		},
+		size: func(elem UR) *big.Int {
+			return genValue.MethodByName("Size").Call([]reflect.Value{rArg(elem)})[0].Interface().(*big.Int)
+		},
+		valueType: t,
+	}
+}
+
+// arbitraryMethod returns the Generator method of t if t implements Arbitrary.
+// The generator may use any representation type R, which is determined by the result of its Random method.
+func arbitraryMethod(t reflect.Type) (reflect.Method, bool) {
+	m, ok := t.MethodByName("Generator")
+	if !ok || m.Type.NumIn() != 1 || m.Type.NumOut() != 1 {
+		return m, false
+	}
+	genType := m.Type.Out(0)
+	random, ok := genType.MethodByName("Random")
+	if !ok || random.Type.NumOut() != 1 {
+		return m, false
+	}
+	rType := random.Type.Out(0)
+	// hasMethod checks the results of a method and that its last parameter is the representation type
+	hasMethod := func(name string, results ...reflect.Type) bool {
+		method, ok := genType.MethodByName(name)
+		if !ok || method.Type.NumIn() == 0 || method.Type.In(method.Type.NumIn()-1) != rType || method.Type.NumOut() != len(results) {
+			return false
+		}
+		for i, r := range results {
+			if r != nil && method.Type.Out(i) != r {
+				return false
+			}
+		}
+		return true
+	}
+	name, ok := genType.MethodByName("Name")
+	if !ok || name.Type.NumOut() != 1 || name.Type.Out(0).Kind() != reflect.String {
+		return m, false
+	}
+	enumerate, ok := genType.MethodByName("Enumerate")
+	if !ok || enumerate.Type.NumOut() != 1 {
+		return m, false
+	}
+	return m, hasMethod("RValue", t, reflect.TypeOf(false)) &&
+		hasMethod("Shrink", nil) &&
+		hasMethod("Size", reflect.TypeOf((*big.Int)(nil)))
+}
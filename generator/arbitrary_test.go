@@ -0,0 +1,95 @@
+package generator_test
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/peterzeller/go-stateful-test/generator"
+	"github.com/peterzeller/go-stateful-test/generator/geniterable"
+	"github.com/stretchr/testify/require"
+)
+
+// Email defines its own generator
+type Email string
+
+func (Email) Generator() generator.Generator[Email, interface{}] {
+	return generator.UntypedR(generator.Map(generator.OneConstantOf("alice", "bob"), func(name string) Email {
+		return Email(name + "@example.com")
+	}))
+}
+
+// Color defines its own generator with a typed representation
+type Color string
+
+func (Color) Generator() generator.Generator[Color, string] {
+	return generator.Map(generator.OneConstantOf("red", "green", "blue"), func(name string) Color {
+		return Color(name)
+	})
+}
+
+type User struct {
+	Name    string
+	Email   Email
+	Aliases []Email
+	Friends map[Email]bool
+}
+
+func TestAny(t *testing.T) {
+	values := geniterable.ToSlice(generator.EnumerateValues(generator.Any[Email](), 2))
+	require.Equal(t, []Email{"alice@example.com", "bob@example.com"}, values)
+
+	ints := geniterable.ToSlice(generator.EnumerateValues(generator.Any[int](), 2))
+	require.Equal(t, []int{0, 1}, ints)
+}
+
+func TestReflectionGenUsesArbitrary(t *testing.T) {
+	users := geniterable.ToSlice(generator.EnumerateValues(generator.Any[User](), 2))
+	require.Contains(t, users, User{Name: "a", Email: "bob@example.com", Aliases: []Email{"alice@example.com"}, Friends: map[Email]bool{"bob@example.com": true}})
+	for _, u := range users {
+		require.True(t, strings.HasSuffix(string(u.Email), "@example.com"))
+		for _, a := range u.Aliases {
+			require.True(t, strings.HasSuffix(string(a), "@example.com"))
+		}
+		for f := range u.Friends {
+			require.True(t, strings.HasSuffix(string(f), "@example.com"))
+		}
+	}
+
+	rnd := testRand{rand.New(rand.NewSource(1))}
+	g := generator.ReflectionGen[[]Email](generator.ReflectionGenDefaultOpts())
+	for i := 0; i < 20; i++ {
+		emails, ok := g.RValue(g.Random(rnd, 5))
+		require.True(t, ok)
+		for _, e := range emails {
+			require.Contains(t, []Email{"alice@example.com", "bob@example.com"}, e)
+		}
+	}
+}
+
+func TestTypedArbitrary(t *testing.T) {
+	colors := geniterable.ToSlice(generator.EnumerateValues(generator.Any[Color](), 3))
+	require.Equal(t, []Color{"red", "green", "blue"}, colors)
+
+	type Pixel struct {
+		X     int
+		Color Color
+	}
+	rnd := testRand{rand.New(rand.NewSource(1))}
+	g := generator.Any[Pixel]()
+	for i := 0; i < 20; i++ {
+		r := g.Random(rnd, 5)
+		p, ok := g.RValue(r)
+		require.True(t, ok)
+		require.Contains(t, colors, p.Color)
+		for it := g.Shrink(r).Iterator(); ; {
+			s, ok := it.Next()
+			if !ok {
+				break
+			}
+			shrunk, ok := g.RValue(s)
+			require.True(t, ok)
+			require.Contains(t, colors, shrunk.Color)
+		}
+	}
+}
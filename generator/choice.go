@@ -0,0 +1,40 @@
+package generator
+
+// Chooser is implemented by Rand implementations that take their randomness from a choice sequence,
+// like the choice sequence engine of quickcheck, which shrinks test runs by lowering the choices.
+//
+// The built-in generators draw their numbers with Choose when the Rand is a Chooser,
+// and they map smaller choices to simpler values, so that lowering a choice never makes a value more complex.
+// Custom generators should use Choose instead of rnd.R() for the same reason.
+type Chooser interface {
+	// Choose returns a number between 0 and n-1, or an arbitrary uint64 if n is 0.
+	// The returned number is the recorded choice, so replaying a smaller choice returns a smaller number.
+	Choose(n uint64) uint64
+}
+
+// Choose returns a random number between 0 and n-1.
+// If rnd is a Chooser, the number is taken directly from the choice sequence, otherwise it is drawn from rnd.R().
+// It panics if n <= 0, like rand.Intn.
+func Choose(rnd Rand, n int) int {
+	if n <= 0 {
+		panic("generator.Choose: n must be positive")
+	}
+	if c, ok := chooser(rnd); ok {
+		return int(c.Choose(uint64(n)))
+	}
+	return rnd.R().Intn(n)
+}
+
+// chooser returns the Chooser of rnd, looking through the wrappers used by the generators of this package.
+func chooser(rnd Rand) (Chooser, bool) {
+	for {
+		switch r := rnd.(type) {
+		case Chooser:
+			return r, true
+		case *recursiveRand:
+			rnd = r.Rand
+		default:
+			return nil, false
+		}
+	}
+}
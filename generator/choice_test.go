@@ -0,0 +1,37 @@
+package generator
+
+import (
+	"math"
+	"testing"
+
+	"github.com/peterzeller/go-fun/hash"
+	"github.com/peterzeller/go-fun/iterable"
+	"github.com/stretchr/testify/require"
+)
+
+// maxChooser is a Chooser that always makes the largest choice
+type maxChooser struct {
+	*testRand
+}
+
+func (c maxChooser) Choose(n uint64) uint64 {
+	if n == 0 {
+		return math.MaxUint64
+	}
+	return n - 1
+}
+
+func TestChoose(t *testing.T) {
+	rnd := maxChooser{newTestRand(1)}
+	require.Equal(t, 2, Choose(rnd, 3))
+	require.Panics(t, func() { Choose(rnd, 0) })
+	require.Panics(t, func() { Choose(newTestRand(1), 0) })
+}
+
+func TestSetChooser(t *testing.T) {
+	g := Set(IntRange(0, 10), hash.Num[int]())
+	rnd := maxChooser{newTestRand(1)}
+	require.Equal(t, 0, iterable.Length[int64](g.Random(rnd, 0)))
+	// with the largest choices, all elements are 10
+	require.Equal(t, 1, iterable.Length[int64](g.Random(rnd, 3)))
+}
@@ -0,0 +1,76 @@
+package generator
+
+import (
+	"math/big"
+
+	"github.com/peterzeller/go-fun/iterable"
+	"github.com/peterzeller/go-stateful-test/generator/geniterable"
+)
+
+// Complex128 generates complex numbers with arbitrary float64 values for the real and imaginary part.
+func Complex128() Generator[complex128, complex128] {
+	return genComplex{part: anyFloat(64)}
+}
+
+// Complex64 generates complex numbers with arbitrary float32 values for the real and imaginary part.
+// The values are represented as complex128, like Float32 represents its values as float64.
+func Complex64() Generator[complex64, complex128] {
+	return Map[complex128, complex64, complex128](genComplex{part: anyFloat(32)}, func(c complex128) complex64 {
+		return complex64(c)
+	})
+}
+
+// genComplex generates complex numbers using the same float generator for the real and imaginary part.
+type genComplex struct {
+	part genFloat
+}
+
+func (g genComplex) Name() string {
+	if g.part.bits == 32 {
+		return "genComplex64"
+	}
+	return "genComplex128"
+}
+
+func (g genComplex) Random(rnd Rand, size int) complex128 {
+	re := g.part.Random(rnd, size)
+	im := g.part.Random(rnd, size)
+	return complex(re, im)
+}
+
+// Shrink shrinks the real part first and then the imaginary part.
+func (g genComplex) Shrink(c complex128) iterable.Iterable[complex128] {
+	re, im := real(c), imag(c)
+	return iterable.Concat(
+		iterable.Map(g.part.Shrink(re), func(re float64) complex128 {
+			return complex(re, im)
+		}),
+		iterable.Map(g.part.Shrink(im), func(im float64) complex128 {
+			return complex(re, im)
+		}))
+}
+
+func (g genComplex) Size(c complex128) *big.Int {
+	res := g.part.Size(real(c))
+	return res.Add(res, g.part.Size(imag(c)))
+}
+
+func (g genComplex) RValue(c complex128) (complex128, bool) {
+	re, ok := g.part.RValue(real(c))
+	if !ok {
+		return 0, false
+	}
+	im, ok := g.part.RValue(imag(c))
+	if !ok {
+		return 0, false
+	}
+	return complex(re, im), true
+}
+
+func (g genComplex) Enumerate(depth int) geniterable.Iterable[complex128] {
+	return geniterable.FlatMap(g.part.Enumerate(depth), func(re float64) geniterable.Iterable[complex128] {
+		return geniterable.Map(g.part.Enumerate(depth), func(im float64) complex128 {
+			return complex(re, im)
+		})
+	})
+}
@@ -8,30 +8,32 @@ import (
 
 // Dict is a generator for immutable dictionaries.
 func Dict[K, RK, V, RV any](keyGen Generator[K, RK], valueGen Generator[V, RV], h hash.EqHash[K]) Generator[hashdict.Dict[K, V], flatmapRv[[]RK, interface{}]] {
-	keys := SliceDistinct[K](keyGen, h)
-	return FlatMap(keys, func(keys []K) Generator[hashdict.Dict[K, V], interface{}] {
-		values := SliceFixedLength(valueGen, len(keys))
-		return Map(values, func(values []V) hashdict.Dict[K, V] {
-			m := hashdict.New[K, V](h)
-			for i, key := range keys {
-				m = m.Set(key, values[i])
-			}
-			return m
-		})
+	return dictOf[K, RK, V, RV, hashdict.Dict[K, V]](SliceDistinct[K, RK](keyGen, h), valueGen, func(keys []K, values []V) hashdict.Dict[K, V] {
+		m := hashdict.New[K, V](h)
+		for i, key := range keys {
+			m = m.Set(key, values[i])
+		}
+		return m
 	})
 }
 
 // DictMut is a generator for mutable dictionaries (maps).
 func DictMut[K comparable, RK, V, RV any](keyGen Generator[K, RK], valueGen Generator[V, RV]) Generator[map[K]V, flatmapRv[[]RK, interface{}]] {
-	keys := SliceDistinct(keyGen, equality.Default[K]())
-	return FlatMap(keys, func(keys []K) Generator[map[K]V, interface{}] {
+	return dictOf(SliceDistinct(keyGen, equality.Default[K]()), valueGen, func(keys []K, values []V) map[K]V {
+		m := make(map[K]V)
+		for i, key := range keys {
+			m[key] = values[i]
+		}
+		return m
+	})
+}
+
+// dictOf generates a value for each of the distinct keys generated by keys and combines them with the function build.
+func dictOf[K, RK, V, RV, D any](keys Generator[[]K, []RK], valueGen Generator[V, RV], build func(keys []K, values []V) D) Generator[D, flatmapRv[[]RK, interface{}]] {
+	return FlatMap(keys, func(keys []K) Generator[D, interface{}] {
 		values := SliceFixedLength(valueGen, len(keys))
-		return Map(values, func(values []V) map[K]V {
-			m := make(map[K]V)
-			for i, key := range keys {
-				m[key] = values[i]
-			}
-			return m
+		return Map(values, func(values []V) D {
+			return build(keys, values)
 		})
 	})
 }
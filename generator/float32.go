@@ -0,0 +1,27 @@
+package generator
+
+import (
+	"math"
+)
+
+// Float32 generates arbitrary float32 values, including special values like NaN, infinities,
+// negative zero, subnormal numbers and the largest finite values.
+func Float32() Generator[float32, float64] {
+	return Map[float64, float32, float64](anyFloat(32), func(f float64) float32 {
+		return float32(f)
+	})
+}
+
+// Float32Range generates float32 values between min and max (inclusive).
+// NaN is never generated and infinities are only generated if they are within the bounds.
+func Float32Range(min float32, max float32) Generator[float32, float64] {
+	return Map[float64, float32, float64](genFloat{
+		min:       float64(min),
+		max:       float64(max),
+		bits:      32,
+		smallest:  math.SmallestNonzeroFloat32,
+		maxFinite: math.MaxFloat32,
+	}, func(f float64) float32 {
+		return float32(f)
+	})
+}
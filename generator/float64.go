@@ -0,0 +1,281 @@
+package generator
+
+import (
+	"math"
+	"math/big"
+
+	"github.com/peterzeller/go-fun/iterable"
+	"github.com/peterzeller/go-stateful-test/generator/geniterable"
+)
+
+// Float64 generates arbitrary float64 values, including special values like NaN, infinities,
+// negative zero, subnormal numbers and the largest finite values.
+func Float64() Generator[float64, float64] {
+	return anyFloat(64)
+}
+
+// anyFloat returns the generator for arbitrary floating point numbers with the given precision (32 or 64 bits).
+func anyFloat(bits int) genFloat {
+	if bits == 32 {
+		return genFloat{
+			min:       math.Inf(-1),
+			max:       math.Inf(1),
+			nan:       true,
+			bits:      32,
+			smallest:  math.SmallestNonzeroFloat32,
+			maxFinite: math.MaxFloat32,
+		}
+	}
+	return genFloat{
+		min:       math.Inf(-1),
+		max:       math.Inf(1),
+		nan:       true,
+		bits:      64,
+		smallest:  math.SmallestNonzeroFloat64,
+		maxFinite: math.MaxFloat64,
+	}
+}
+
+// Float64Range generates float64 values between min and max (inclusive).
+// NaN is never generated and infinities are only generated if they are within the bounds.
+func Float64Range(min float64, max float64) Generator[float64, float64] {
+	return genFloat{
+		min:       min,
+		max:       max,
+		bits:      64,
+		smallest:  math.SmallestNonzeroFloat64,
+		maxFinite: math.MaxFloat64,
+	}
+}
+
+// genFloat generates floating point numbers.
+// The same implementation is used for float32 values, which are represented as float64 values with bits = 32.
+type genFloat struct {
+	min, max float64
+	// nan is true if NaN values should be generated
+	nan bool
+	// bits is 32 or 64 depending on the precision
+	bits int
+	// smallest positive subnormal number and the largest finite number for the precision
+	smallest, maxFinite float64
+}
+
+func (g genFloat) Name() string {
+	if g.bits == 32 {
+		return "genFloat32"
+	}
+	return "genFloat64"
+}
+
+// specialValues returns interesting values within the bounds, simple values first.
+func (g genFloat) specialValues() []float64 {
+	candidates := []float64{
+		0, 1, -1, 0.5, -0.5, 2, -2, 1.5, -1.5, 10, -10, 0.1, -0.1,
+		math.Copysign(0, -1),
+		math.Inf(1), math.Inf(-1),
+		g.smallest, -g.smallest,
+		g.maxFinite, -g.maxFinite,
+		g.min, g.max,
+	}
+	res := make([]float64, 0, len(candidates)+1)
+	seen := make(map[uint64]bool)
+	for _, c := range candidates {
+		if g.inRange(c) && !seen[math.Float64bits(c)] {
+			seen[math.Float64bits(c)] = true
+			res = append(res, c)
+		}
+	}
+	if g.nan {
+		res = append(res, math.NaN())
+	}
+	return res
+}
+
+func (g genFloat) inRange(v float64) bool {
+	return g.min <= v && v <= g.max
+}
+
+func (g genFloat) Random(rnd Rand, size int) float64 {
+	if c, ok := chooser(rnd); ok {
+		return g.choose(c)
+	}
+	r := rnd.R()
+	p := r.Float64()
+	switch {
+	case p < 0.2:
+		// special values
+		special := g.specialValues()
+		return special[r.Intn(len(special))]
+	case p < 0.6:
+		// simple numbers with few decimal places around 0
+		v := roundDecimals(r.NormFloat64()*float64(size+1), r.Intn(4))
+		if g.inRange(v) {
+			return g.normalize(v)
+		}
+		return g.uniform(r.Float64())
+	case p < 0.8:
+		return g.uniform(r.Float64())
+	default:
+		// arbitrary bit pattern
+		var v float64
+		if g.bits == 32 {
+			v = float64(math.Float32frombits(r.Uint32()))
+		} else {
+			v = math.Float64frombits(r.Uint64())
+		}
+		if math.IsNaN(v) {
+			if g.nan {
+				return v
+			}
+			return g.uniform(r.Float64())
+		}
+		if !g.inRange(v) {
+			return g.uniform(r.Float64())
+		}
+		return v
+	}
+}
+
+// choose generates a value from a choice sequence.
+// The first choice selects the kind of value, ordered from simple to complex like the branches of Random.
+func (g genFloat) choose(c Chooser) float64 {
+	switch c.Choose(4) {
+	case 0:
+		special := g.specialValues()
+		return special[c.Choose(uint64(len(special)))]
+	case 1:
+		// integers divided by a power of ten, ordered by their distance to 0
+		i := genInt64{min: math.MinInt64, max: math.MaxInt64}.fromIndex(c.Choose(0))
+		return g.normalize(float64(i) / math.Pow(10, float64(c.Choose(4))))
+	case 2:
+		return g.uniform(float64(c.Choose(1<<53)) / (1 << 53))
+	default:
+		var v float64
+		if g.bits == 32 {
+			v = float64(math.Float32frombits(uint32(c.Choose(1 << 32))))
+		} else {
+			v = math.Float64frombits(c.Choose(0))
+		}
+		if math.IsNaN(v) {
+			if g.nan {
+				return v
+			}
+			return g.normalize(0)
+		}
+		return g.normalize(v)
+	}
+}
+
+// uniform maps p in [0, 1) to the finite part of the range
+func (g genFloat) uniform(p float64) float64 {
+	lo := math.Max(g.min, -g.maxFinite)
+	hi := math.Min(g.max, g.maxFinite)
+	// halve the bounds to avoid overflows
+	return g.normalize(lo + p*(hi/2-lo/2)*2)
+}
+
+// normalize rounds v to the precision of the generator and clamps it to the bounds
+func (g genFloat) normalize(v float64) float64 {
+	if g.bits == 32 {
+		v = float64(float32(v))
+	}
+	if v < g.min {
+		return g.min
+	}
+	if v > g.max {
+		return g.max
+	}
+	return v
+}
+
+// roundDecimals rounds v to the given number of decimal places
+func roundDecimals(v float64, decimals int) float64 {
+	p := math.Pow(10, float64(decimals))
+	res := math.Round(v*p) / p
+	if math.IsNaN(res) || math.IsInf(res, 0) {
+		return v
+	}
+	return res
+}
+
+// decimalPlaces returns the number of decimal places needed to represent v (at most 20)
+func decimalPlaces(v float64) int {
+	for d := 0; d < 20; d++ {
+		if roundDecimals(v, d) == v {
+			return d
+		}
+	}
+	return 20
+}
+
+func (g genFloat) Enumerate(depth int) geniterable.Iterable[float64] {
+	// special values are ordered with simple values first
+	return geniterable.NonExhaustive(geniterable.Take(depth, geniterable.FromSlice(g.specialValues())))
+}
+
+// Shrink tries simpler values: 0, the positive value, integers, and values with fewer decimal places.
+func (g genFloat) Shrink(v float64) iterable.Iterable[float64] {
+	var candidates []float64
+	switch {
+	case math.IsNaN(v):
+		candidates = []float64{0, 1, math.Inf(1)}
+	case math.IsInf(v, 0):
+		candidates = []float64{0, math.Copysign(g.maxFinite, v)}
+	case v == 0:
+		return iterable.Empty[float64]()
+	default:
+		candidates = append(candidates, 0)
+		if v < 0 {
+			candidates = append(candidates, -v)
+		}
+		candidates = append(candidates, math.Trunc(v))
+		for d := 1; d < decimalPlaces(v); d++ {
+			candidates = append(candidates, roundDecimals(v, d))
+		}
+		if math.Abs(v) >= 2 {
+			candidates = append(candidates, math.Trunc(v/2))
+		}
+		candidates = append(candidates, v/2)
+	}
+	size := g.Size(v)
+	res := make([]float64, 0, len(candidates))
+	for _, c := range candidates {
+		if g.inRange(c) && g.normalize(c) == c && g.Size(c).Cmp(size) < 0 {
+			res = append(res, c)
+		}
+	}
+	return iterable.FromSlice(res)
+}
+
+func (g genFloat) RValue(v float64) (float64, bool) {
+	if g.min > g.max {
+		return 0, false
+	}
+	if math.IsNaN(v) {
+		if g.nan {
+			return v, true
+		}
+		return g.min, true
+	}
+	return g.normalize(v), true
+}
+
+// Size of a float is based on the integer part, the number of decimal places, and the sign.
+// NaN and infinities are larger than all finite values.
+func (g genFloat) Size(v float64) *big.Int {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		res := g.Size(g.maxFinite)
+		res.Add(res, big.NewInt(1))
+		if math.IsNaN(v) {
+			res.Add(res, big.NewInt(1))
+		}
+		return res
+	}
+	intPart, _ := big.NewFloat(math.Trunc(math.Abs(v))).Int(nil)
+	res := intPart.Mul(intPart, big.NewInt(32))
+	res.Add(res, big.NewInt(int64(decimalPlaces(v))))
+	if math.Signbit(v) {
+		res.Add(res, big.NewInt(1))
+	}
+	return res
+}
@@ -0,0 +1,86 @@
+package generator
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+
+	"github.com/peterzeller/go-fun/iterable"
+	"github.com/peterzeller/go-stateful-test/generator/geniterable"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFloat64_Random(t *testing.T) {
+	g := Float64()
+	rnd := newTestRand(1)
+	var nan, inf, frac bool
+	for i := 0; i < 1000; i++ {
+		v := g.Random(rnd, 10)
+		nan = nan || math.IsNaN(v)
+		inf = inf || math.IsInf(v, 0)
+		frac = frac || (v != math.Trunc(v) && !math.IsInf(v, 0))
+	}
+	require.True(t, nan, "NaN")
+	require.True(t, inf, "Inf")
+	require.True(t, frac, "fractions")
+}
+
+func TestFloat64Range_Random(t *testing.T) {
+	g := Float64Range(-2.5, 1e300)
+	rnd := newTestRand(1)
+	for i := 0; i < 1000; i++ {
+		v := g.Random(rnd, 10)
+		require.False(t, math.IsNaN(v))
+		require.True(t, -2.5 <= v && v <= 1e300, "%v out of range", v)
+	}
+}
+
+func TestFloat64_Enumerate(t *testing.T) {
+	g := Float64Range(-1, 1)
+	require.Equal(t, "[0, 1, -1, ...]", geniterable.String(EnumerateValues(g, 3)))
+}
+
+func TestFloat64_Shrink(t *testing.T) {
+	g := Float64()
+	require.Equal(t, []float64{0, 3, 3.1, 3.14, 3.142, 3.1416, 1, 1.570795}, iterable.ToSlice(g.Shrink(3.14159)))
+	require.Equal(t, []float64{0, 2.5, -2, -1, -1.25}, iterable.ToSlice(g.Shrink(-2.5)))
+	require.Equal(t, []float64{0, math.MaxFloat64}, iterable.ToSlice(g.Shrink(math.Inf(1))))
+	require.Empty(t, iterable.ToSlice(g.Shrink(0)))
+}
+
+func TestFloat32_Random(t *testing.T) {
+	g := Float32Range(-1e30, 1e30)
+	rnd := newTestRand(1)
+	for i := 0; i < 1000; i++ {
+		v, ok := g.RValue(g.Random(rnd, 10))
+		require.True(t, ok)
+		require.True(t, -1e30 <= v && v <= 1e30, "%v out of range", v)
+	}
+}
+
+func TestComplex128_Shrink(t *testing.T) {
+	g := Complex128()
+	rnd := newTestRand(2)
+	var r complex128
+	for real(r) == 0 || imag(r) == 0 || math.IsNaN(real(r)) {
+		r = g.Random(rnd, 10)
+	}
+	shrinks := iterable.ToSlice(g.Shrink(r))
+	require.NotEmpty(t, shrinks)
+	v, _ := g.RValue(shrinks[0])
+	require.Equal(t, 0.0, real(v))
+}
+
+func TestComplex64(t *testing.T) {
+	// the representation is a complex128, which is converted to complex64
+	var g Generator[complex64, complex128] = Complex64()
+	rnd := newTestRand(3)
+	for i := 0; i < 100; i++ {
+		r := g.Random(rnd, 10)
+		v, ok := g.RValue(r)
+		require.True(t, ok)
+		if !cmplx.IsNaN(r) {
+			require.Equal(t, complex64(r), v)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package generator
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/peterzeller/go-fun/iterable"
+	"github.com/peterzeller/go-fun/zero"
+	"github.com/peterzeller/go-stateful-test/generator/geniterable"
+)
+
+// WeightedGen is a generator with a weight for Frequency.
+type WeightedGen[T, R any] struct {
+	Weight int
+	Gen    Generator[T, R]
+}
+
+// Weighted combines a weight and a generator for Frequency.
+func Weighted[T, R any](weight int, gen Generator[T, R]) WeightedGen[T, R] {
+	return WeightedGen[T, R]{Weight: weight, Gen: gen}
+}
+
+// Frequency is like OneOf, but chooses the generators with a probability proportional to their weight.
+// Generators with weight 0 are never chosen randomly, but are still enumerated.
+// If all weights are 0, the generators are chosen uniformly, like with OneOf.
+// Enumeration ignores the weights and keeps the order of the generators.
+// When shrinking, values from earlier generators are preferred, so the most important alternative should be listed first.
+func Frequency[T, R any](gs ...WeightedGen[T, R]) Generator[T, OneOfRandom[R]] {
+	if len(gs) == 0 {
+		return Empty[T, OneOfRandom[R]]()
+	}
+	total := 0
+	for _, g := range gs {
+		if g.Weight < 0 {
+			panic(fmt.Errorf("negative weight %d for generator %s", g.Weight, g.Gen.Name()))
+		}
+		total += g.Weight
+	}
+	return &AnonGenerator[T, OneOfRandom[R]]{
+		GenName: "Frequency",
+		GenRandom: func(rnd Rand, size int) OneOfRandom[R] {
+			n := 0
+			if total == 0 {
+				// all weights are 0
+				n = Choose(rnd, len(gs))
+			} else {
+				w := Choose(rnd, total)
+				for w >= gs[n].Weight {
+					w -= gs[n].Weight
+					n++
+				}
+			}
+			return OneOfRandom[R]{
+				generator: n,
+				value:     gs[n].Gen.Random(rnd, size),
+			}
+		},
+		GenEnumerate: func(depth int) geniterable.Iterable[OneOfRandom[R]] {
+			return geniterable.FlatMapBreadthFirst(geniterable.Range(0, len(gs)),
+				func(i int) geniterable.Iterable[OneOfRandom[R]] {
+					return geniterable.Map(gs[i].Gen.Enumerate(depth), func(a R) OneOfRandom[R] {
+						return OneOfRandom[R]{
+							generator: i,
+							value:     a,
+						}
+					})
+				})
+		},
+		GenShrink: func(elem OneOfRandom[R]) iterable.Iterable[OneOfRandom[R]] {
+			if elem.generator < 0 || elem.generator >= len(gs) {
+				return iterable.Empty[OneOfRandom[R]]()
+			}
+			// first try the simplest value of each earlier generator
+			var earlier []OneOfRandom[R]
+			for i := 0; i < elem.generator; i++ {
+				if first := gs[i].Gen.Enumerate(1).Iterator().Next(); first.Present() {
+					earlier = append(earlier, OneOfRandom[R]{generator: i, value: first.Value()})
+				}
+			}
+			return iterable.Concat(
+				iterable.FromSlice(earlier),
+				iterable.Map(
+					gs[elem.generator].Gen.Shrink(elem.value),
+					func(v R) OneOfRandom[R] {
+						return OneOfRandom[R]{
+							generator: elem.generator,
+							value:     v,
+						}
+					}))
+		},
+		GenSize: func(rv OneOfRandom[R]) *big.Int {
+			if rv.generator < 0 || rv.generator >= len(gs) {
+				return big.NewInt(0)
+			}
+			// later generators are larger
+			size := gs[rv.generator].Gen.Size(rv.value)
+			return size.Add(size, big.NewInt(int64(rv.generator)))
+		},
+		GenRValue: func(rv OneOfRandom[R]) (T, bool) {
+			if rv.generator < 0 || rv.generator >= len(gs) {
+				return zero.Value[T](), false
+			}
+			return gs[rv.generator].Gen.RValue(rv.value)
+		},
+	}
+}
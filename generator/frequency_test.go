@@ -0,0 +1,64 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/peterzeller/go-fun/iterable"
+	"github.com/peterzeller/go-stateful-test/generator/geniterable"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFrequency_Random(t *testing.T) {
+	g := Frequency(
+		Weighted(9, Constant("common")),
+		Weighted(1, Constant("rare")),
+		Weighted(0, Constant("never")))
+	rnd := newTestRand(1)
+	counts := make(map[string]int)
+	for i := 0; i < 1000; i++ {
+		v, ok := g.RValue(g.Random(rnd, 10))
+		require.True(t, ok)
+		counts[v]++
+	}
+	require.Zero(t, counts["never"])
+	require.Greater(t, counts["common"], 800)
+	require.Greater(t, counts["rare"], 50)
+}
+
+func TestFrequency_RandomAllZero(t *testing.T) {
+	g := Frequency(
+		Weighted(0, Constant("a")),
+		Weighted(0, Constant("b")))
+	rnd := newTestRand(1)
+	counts := make(map[string]int)
+	for i := 0; i < 1000; i++ {
+		v, ok := g.RValue(g.Random(rnd, 10))
+		require.True(t, ok)
+		counts[v]++
+	}
+	require.Greater(t, counts["a"], 400)
+	require.Greater(t, counts["b"], 400)
+}
+
+func TestFrequency_Enumerate(t *testing.T) {
+	g := Frequency(
+		Weighted(1, IntRange(0, 1)),
+		Weighted(100, IntRange(10, 11)),
+		Weighted(0, IntRange(20, 20)))
+	require.Equal(t, "[0, 10, 20, 1, 11]", geniterable.String(EnumerateValues(g, 5)))
+}
+
+func TestFrequency_Shrink(t *testing.T) {
+	g := Frequency(
+		Weighted(1, IntRange(0, 1)),
+		Weighted(100, IntRange(10, 20)))
+	r := OneOfRandom[int64]{generator: 1, value: 14}
+	var shrinks []int
+	for _, s := range iterable.ToSlice(g.Shrink(r)) {
+		v, _ := g.RValue(s)
+		shrinks = append(shrinks, v)
+		require.Negative(t, g.Size(s).Cmp(g.Size(r)))
+	}
+	// the earlier generator is tried first
+	require.Equal(t, []int{0, 10, 13}, shrinks)
+}
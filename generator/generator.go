@@ -16,6 +16,7 @@ import (
 	"github.com/peterzeller/go-stateful-test/generator/geniterable"
 	"math/big"
 	"math/rand"
+	"reflect"
 )
 
 // Generator is an interface for generating values of type T with internal value representation R.
@@ -60,6 +61,17 @@ type untypedGen struct {
 	rvalue    func(elem UR) (UV, bool)
 	size      func(elem UR) *big.Int
 	enumerate func(depth int) geniterable.Iterable[UR]
+	// valueType is the type of the values in UV
+	valueType reflect.Type
+}
+
+// ValueType returns the type of the values of an UntypedGenerator, or nil if it is not known.
+// For a generator created with ToUntyped from a Generator[T, R], this is the static type T.
+func ValueType(g UntypedGenerator) reflect.Type {
+	if u, ok := g.(untypedGen); ok {
+		return u.valueType
+	}
+	return nil
 }
 
 func (u untypedGen) Size(value UR) *big.Int {
@@ -113,6 +125,7 @@ func ToUntyped[T, R any](gen Generator[T, R]) UntypedGenerator {
 		size: func(elem UR) *big.Int {
 			return gen.Size(unwrapR(elem))
 		},
+		valueType: reflect.TypeOf((*T)(nil)).Elem(),
 	}
 }
 
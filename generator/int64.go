@@ -31,6 +31,9 @@ func (g genInt64) Name() string {
 }
 
 func (g genInt64) Random(rnd Rand, size int) int64 {
+	if c, ok := chooser(rnd); ok {
+		return g.fromIndex(c.Choose(uint64(g.max) - uint64(g.min) + 1))
+	}
 	r := rnd.R()
 	p := r.Float64()
 	n := 1 + g.max - g.min
@@ -63,6 +66,34 @@ func (g genInt64) Random(rnd Rand, size int) int64 {
 	}
 }
 
+// fromIndex maps indexes to the values of the range ordered by their distance to 0: 0, 1, -1, 2, -2, ...
+// If the range does not contain 0, the values closest to 0 come first.
+func (g genInt64) fromIndex(k uint64) int64 {
+	switch {
+	case g.min >= 0:
+		return g.min + int64(k)
+	case g.max <= 0:
+		return g.max - int64(k)
+	}
+	pos, neg := uint64(g.max), uint64(-(g.min+1))+1
+	m := pos
+	if neg < m {
+		m = neg
+	}
+	switch {
+	case k == 0:
+		return 0
+	case k <= 2*m && k%2 == 1:
+		return int64((k + 1) / 2)
+	case k <= 2*m:
+		return -int64(k / 2)
+	case pos > neg:
+		return int64(k - m)
+	default:
+		return -int64(k - m)
+	}
+}
+
 func (g genInt64) Enumerate(depth int) geniterable.Iterable[int64] {
 	if g.min < 0 && g.max > 0 {
 		return geniterable.TakeExhaustive(depth,
@@ -17,7 +17,7 @@ func OneOf[T, R any](gs ...Generator[T, R]) Generator[T, OneOfRandom[R]] {
 	return &AnonGenerator[T, OneOfRandom[R]]{
 		GenName: "OneOf",
 		GenRandom: func(rnd Rand, size int) OneOfRandom[R] {
-			n := rnd.R().Intn(len(gs))
+			n := Choose(rnd, len(gs))
 			g := gs[n]
 			return OneOfRandom[R]{
 				generator: n,
@@ -78,7 +78,7 @@ func OneConstantOf[T comparable](values ...T) Generator[T, T] {
 	return &AnonGenerator[T, T]{
 		GenName: "OneConstantOf",
 		GenRandom: func(rnd Rand, size int) T {
-			n := rnd.R().Intn(len(values))
+			n := Choose(rnd, len(values))
 			g := values[n]
 			return g
 		},
@@ -0,0 +1,203 @@
+package generator
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/peterzeller/go-fun/iterable"
+	"github.com/peterzeller/go-stateful-test/generator/geniterable"
+)
+
+// Lazy creates a generator that is constructed on first use.
+// This can be used to define generators that refer to each other.
+// For generators that refer to themselves, use Recursive, which also limits the depth of the generated values.
+func Lazy[T, R any](f func() Generator[T, R]) Generator[T, R] {
+	return &lazyGen[T, R]{f: f}
+}
+
+type lazyGen[T, R any] struct {
+	once sync.Once
+	f    func() Generator[T, R]
+	gen  Generator[T, R]
+}
+
+func (l *lazyGen[T, R]) get() Generator[T, R] {
+	l.once.Do(func() {
+		l.gen = l.f()
+	})
+	return l.gen
+}
+
+// Name does not force the generator, because the name of a recursive generator would be infinite.
+func (l *lazyGen[T, R]) Name() string {
+	return "Lazy"
+}
+
+func (l *lazyGen[T, R]) Random(rnd Rand, size int) R {
+	return l.get().Random(rnd, size)
+}
+
+func (l *lazyGen[T, R]) Shrink(elem R) iterable.Iterable[R] {
+	return l.get().Shrink(elem)
+}
+
+func (l *lazyGen[T, R]) Size(elem R) *big.Int {
+	return l.get().Size(elem)
+}
+
+func (l *lazyGen[T, R]) RValue(elem R) (T, bool) {
+	return l.get().RValue(elem)
+}
+
+func (l *lazyGen[T, R]) Enumerate(depth int) geniterable.Iterable[R] {
+	return l.get().Enumerate(depth)
+}
+
+// Recursive creates a generator for recursive data types like trees.
+// The function f gets the generator itself as parameter and must return a generator that uses it for the recursive parts.
+// Since Go does not support infinite types, the representation type is usually interface{} (see UntypedR).
+//
+// The generator limits the size of the generated values:
+// Random divides the size budget randomly between the recursive calls and uses the smallest value when the budget is exhausted,
+// Enumerate decreases the depth for every recursive call,
+// and Shrink first tries to replace a value with one of its direct children.
+func Recursive[T, R any](f func(self Generator[T, R]) Generator[T, R]) Generator[T, R] {
+	return &recursiveGen[T, R]{f: f}
+}
+
+type recursiveGen[T, R any] struct {
+	once  sync.Once
+	f     func(self Generator[T, R]) Generator[T, R]
+	inner Generator[T, R]
+}
+
+func (g *recursiveGen[T, R]) get() Generator[T, R] {
+	g.once.Do(func() {
+		g.inner = g.f(recursiveSelf[T, R]{gen: g})
+	})
+	return g.inner
+}
+
+func (g *recursiveGen[T, R]) Name() string {
+	return fmt.Sprintf("Recursive(%s)", g.get().Name())
+}
+
+func (g *recursiveGen[T, R]) Random(rnd Rand, size int) R {
+	return g.randomWithBudget(rnd, size)
+}
+
+// randomWithBudget generates a value in which at most budget recursive calls generate non-minimal values.
+func (g *recursiveGen[T, R]) randomWithBudget(rnd Rand, budget int) R {
+	return g.get().Random(&recursiveRand{Rand: rnd, gen: g, budget: &budget}, budget)
+}
+
+// smallest returns the first value in the enumeration, which usually is a non-recursive base case.
+func (g *recursiveGen[T, R]) smallest() R {
+	for depth := 1; depth <= 10; depth++ {
+		if first := g.Enumerate(depth).Iterator().Next(); first.Present() {
+			return first.Value()
+		}
+	}
+	panic(fmt.Errorf("generator %s has no values without recursion", g.Name()))
+}
+
+func (g *recursiveGen[T, R]) Enumerate(depth int) geniterable.Iterable[R] {
+	return g.get().Enumerate(depth)
+}
+
+// Shrink tries to replace the value with one of its children first and then shrinks the value structurally.
+func (g *recursiveGen[T, R]) Shrink(elem R) iterable.Iterable[R] {
+	return iterable.Concat(
+		iterable.FromSlice(g.children(elem)),
+		g.get().Shrink(elem))
+}
+
+// children returns the direct recursive children of the value.
+// To find them, we convert the value using a copy of the generator that records the values converted by self.
+func (g *recursiveGen[T, R]) children(elem R) []R {
+	var children []R
+	recording := g.f(recursiveSelf[T, R]{gen: g, record: &children})
+	if _, ok := recording.RValue(elem); !ok {
+		return nil
+	}
+	return children
+}
+
+// Size adds 1 for every recursive value, so that replacing a value with one of its children makes it smaller.
+func (g *recursiveGen[T, R]) Size(elem R) *big.Int {
+	size := g.get().Size(elem)
+	return size.Add(size, big.NewInt(1))
+}
+
+func (g *recursiveGen[T, R]) RValue(elem R) (T, bool) {
+	return g.get().RValue(elem)
+}
+
+// recursiveRand passes the remaining size budget of a recursive generator to its recursive calls.
+type recursiveRand struct {
+	Rand
+	gen    interface{}
+	budget *int
+}
+
+// findBudget returns the remaining budget for the given generator, if rnd was created by it.
+func findBudget(rnd Rand, gen interface{}) (*recursiveRand, bool) {
+	for {
+		r, ok := rnd.(*recursiveRand)
+		if !ok {
+			return nil, false
+		}
+		if r.gen == gen {
+			return r, true
+		}
+		rnd = r.Rand
+	}
+}
+
+// recursiveSelf is the generator passed to the function of a recursive generator.
+type recursiveSelf[T, R any] struct {
+	gen *recursiveGen[T, R]
+	// record collects the values converted with RValue, if not nil
+	record *[]R
+}
+
+func (s recursiveSelf[T, R]) Name() string {
+	return "self"
+}
+
+func (s recursiveSelf[T, R]) Random(rnd Rand, size int) R {
+	r, ok := findBudget(rnd, s.gen)
+	if !ok {
+		return s.gen.Random(rnd, size)
+	}
+	if *r.budget <= 0 {
+		return s.gen.smallest()
+	}
+	// take a random part of the remaining budget for this call
+	share := 1 + Choose(rnd, *r.budget)
+	*r.budget -= share
+	return s.gen.randomWithBudget(r.Rand, share-1)
+}
+
+func (s recursiveSelf[T, R]) Enumerate(depth int) geniterable.Iterable[R] {
+	if depth <= 1 {
+		return geniterable.NonExhaustive(geniterable.Empty[R]())
+	}
+	return s.gen.Enumerate(depth - 1)
+}
+
+func (s recursiveSelf[T, R]) Shrink(elem R) iterable.Iterable[R] {
+	return s.gen.Shrink(elem)
+}
+
+func (s recursiveSelf[T, R]) Size(elem R) *big.Int {
+	return s.gen.Size(elem)
+}
+
+func (s recursiveSelf[T, R]) RValue(elem R) (T, bool) {
+	if s.record != nil {
+		*s.record = append(*s.record, elem)
+	}
+	return s.gen.RValue(elem)
+}
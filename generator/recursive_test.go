@@ -0,0 +1,101 @@
+package generator
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/peterzeller/go-fun/iterable"
+	"github.com/peterzeller/go-stateful-test/generator/geniterable"
+	"github.com/stretchr/testify/require"
+)
+
+type testTree struct {
+	Left, Right *testTree
+	Value       int
+}
+
+func (t *testTree) String() string {
+	if t.Left == nil {
+		return fmt.Sprintf("%d", t.Value)
+	}
+	return fmt.Sprintf("(%v %v)", t.Left, t.Right)
+}
+
+func (t *testTree) nodes() int {
+	if t.Left == nil {
+		return 1
+	}
+	return 1 + t.Left.nodes() + t.Right.nodes()
+}
+
+func genTestTree() Generator[*testTree, interface{}] {
+	return Recursive(func(self Generator[*testTree, interface{}]) Generator[*testTree, interface{}] {
+		return UntypedR(OneOf(
+			UntypedR(Map(IntRange(0, 9), func(v int) *testTree {
+				return &testTree{Value: v}
+			})),
+			UntypedR(Zip(self, self, func(l, r *testTree) *testTree {
+				return &testTree{Left: l, Right: r}
+			}))))
+	})
+}
+
+func TestRecursive_Random(t *testing.T) {
+	g := genTestTree()
+	rnd := newTestRand(1)
+	maxNodes := 0
+	for i := 0; i < 200; i++ {
+		v, ok := g.RValue(g.Random(rnd, 10))
+		require.True(t, ok)
+		// every inner node uses a part of the budget
+		require.LessOrEqual(t, v.nodes(), 2*11+1, "%v", v)
+		if v.nodes() > maxNodes {
+			maxNodes = v.nodes()
+		}
+	}
+	require.Greater(t, maxNodes, 5)
+}
+
+func TestRecursive_Enumerate(t *testing.T) {
+	g := genTestTree()
+	values := geniterable.ToSlice(EnumerateValues(g, 2))
+	strs := make([]string, len(values))
+	for i, v := range values {
+		strs[i] = v.String()
+	}
+	// the recursive calls use depth 1
+	require.Equal(t, []string{"0", "(0 0)", "1"}, strs)
+	require.False(t, geniterable.IsExhaustive(EnumerateValues(g, 2)))
+}
+
+func TestRecursive_Shrink(t *testing.T) {
+	g := genTestTree()
+	rnd := newTestRand(2)
+	var r interface{}
+	for {
+		r = g.Random(rnd, 10)
+		if v, _ := g.RValue(r); v.nodes() >= 5 {
+			break
+		}
+	}
+	v, _ := g.RValue(r)
+	shrinks := iterable.ToSlice(g.Shrink(r))
+	// the first candidates are the children
+	left, _ := g.RValue(shrinks[0])
+	right, _ := g.RValue(shrinks[1])
+	require.Equal(t, v.Left.String(), left.String())
+	require.Equal(t, v.Right.String(), right.String())
+	for _, s := range shrinks {
+		_, ok := g.RValue(s)
+		require.True(t, ok)
+	}
+}
+
+func TestLazy(t *testing.T) {
+	var g Generator[[]int, []int64]
+	g = Lazy(func() Generator[[]int, []int64] {
+		return Slice(IntRange(0, 3))
+	})
+	require.Equal(t, "Lazy", g.Name())
+	require.Equal(t, "[[], [0], [1], [0 0], [1 0], [0 1], [1 1], ...]", geniterable.String(EnumerateValues(g, 2)))
+}
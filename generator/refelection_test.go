@@ -3,6 +3,7 @@ package generator_test
 import (
 	"fmt"
 	"github.com/peterzeller/go-stateful-test/generator/geniterable"
+	"math/rand"
 	"testing"
 
 	"github.com/peterzeller/go-stateful-test/generator"
@@ -111,3 +112,223 @@ func ExampleReflectionGeneratorOptions_RegisterConstructor() {
 	// (-1, 1)
 	// (-1, -1)
 }
+
+type Point struct {
+	X float64
+	Y float32
+	Z complex128
+}
+
+func TestReflectionGenFloats(t *testing.T) {
+	g := generator.ReflectionGen[Point](generator.ReflectionGenDefaultOpts())
+	values := geniterable.ToSlice(generator.EnumerateValues(g, 2))
+	require.Contains(t, values, Point{X: 0, Y: 1, Z: complex(1, 0)})
+}
+
+func TestReflectionGenMap(t *testing.T) {
+	g := generator.ReflectionGen[map[string]bool](generator.ReflectionGenDefaultOpts())
+	values := geniterable.ToSlice(generator.EnumerateValues(g, 2))
+	require.Contains(t, values, map[string]bool{})
+	require.Contains(t, values, map[string]bool{"a": true})
+	require.Contains(t, values, map[string]bool{"": false, "b": true})
+}
+
+func TestReflectionGenPointer(t *testing.T) {
+	g := generator.ReflectionGen[*int](generator.ReflectionGenDefaultOpts())
+	values := geniterable.ToSlice(generator.EnumerateValues(g, 2))
+	require.Len(t, values, 3)
+	require.Nil(t, values[0])
+	require.Equal(t, 0, *values[1])
+	require.Equal(t, 1, *values[2])
+}
+
+func TestReflectionGenArray(t *testing.T) {
+	g := generator.ReflectionGen[[2]bool](generator.ReflectionGenDefaultOpts())
+	values := geniterable.ToSlice(generator.EnumerateValues(g, 2))
+	require.ElementsMatch(t, [][2]bool{{false, false}, {false, true}, {true, false}, {true, true}}, values)
+}
+
+type Shape interface {
+	Area() int
+}
+
+type Square struct {
+	Side int
+}
+
+func (s Square) Area() int {
+	return s.Side * s.Side
+}
+
+type Rect struct {
+	W, H int
+}
+
+func (r *Rect) Area() int {
+	return r.W * r.H
+}
+
+type Drawing struct {
+	Shapes []Shape
+	Origin [2]bool
+	Labels map[bool]*Square
+}
+
+func TestReflectionGenInterface(t *testing.T) {
+	opts := generator.ReflectionGenDefaultOpts()
+	opts.RegisterImplementations((*Shape)(nil), Square{}, &Rect{})
+	g := generator.ReflectionGen[Shape](opts)
+	values := geniterable.ToSlice(generator.EnumerateValues(g, 2))
+	require.Contains(t, values, Square{Side: 1})
+	require.Contains(t, values, &Rect{W: 1, H: 0})
+
+	d := generator.ReflectionGen[Drawing](opts)
+	drawings := geniterable.ToSlice(generator.EnumerateValues(d, 2))
+	require.Contains(t, drawings, Drawing{Shapes: []Shape{Square{Side: 1}}, Origin: [2]bool{false, true}, Labels: map[bool]*Square{true: nil}})
+}
+
+func TestReflectionGenUnsupported(t *testing.T) {
+	require.PanicsWithError(t, "error creating generator for struct , field Shapes: generating element generator for generator_test.Shape slice: no generator found for type generator_test.Shape", func() {
+		generator.ReflectionGen[struct{ Shapes []Shape }](generator.ReflectionGenDefaultOpts())
+	})
+	require.Panics(t, func() {
+		generator.ReflectionGenDefaultOpts().RegisterImplementations((*Shape)(nil), Rect{})
+	})
+}
+
+func TestReflectionGenFieldsUseRegistry(t *testing.T) {
+	opts := generator.ReflectionGenDefaultOpts()
+	opts.RegisterConstructor(func() string { return "constant" })
+	g := generator.ReflectionGen[Pair](opts)
+	for it := geniterable.Start(generator.EnumerateValues(g, 2)); it.HasNext(); it.Next() {
+		require.Equal(t, "constant", it.Current().A)
+	}
+}
+
+type Tree struct {
+	Left, Right *Tree
+	Value       int
+}
+
+func (tr *Tree) size() int {
+	if tr == nil {
+		return 0
+	}
+	return 1 + tr.Left.size() + tr.Right.size()
+}
+
+type Expr interface {
+	eval() int
+}
+
+type Lit struct {
+	V int
+}
+
+func (l Lit) eval() int {
+	return l.V
+}
+
+type Add struct {
+	L, R Expr
+}
+
+func (a Add) eval() int {
+	return a.L.eval() + a.R.eval()
+}
+
+func TestReflectionGenRecursive(t *testing.T) {
+	g := generator.ReflectionGen[Tree](generator.ReflectionGenDefaultOpts())
+	values := geniterable.ToSlice(generator.EnumerateValues(g, 3))
+	require.Contains(t, values, Tree{Value: 1})
+	require.Contains(t, values, Tree{Left: &Tree{Value: 1}})
+	rnd := testRand{rand.New(rand.NewSource(1))}
+	for i := 0; i < 100; i++ {
+		v, ok := g.RValue(g.Random(rnd, 10))
+		require.True(t, ok)
+		// every node that is not a leaf uses part of the size budget
+		require.LessOrEqual(t, v.size(), 1+3*10)
+	}
+
+	opts := generator.ReflectionGenDefaultOpts()
+	opts.RegisterImplementations((*Expr)(nil), Lit{}, Add{})
+	e := generator.ReflectionGen[Expr](opts)
+	exprs := geniterable.ToSlice(generator.EnumerateValues(e, 3))
+	require.Contains(t, exprs, Add{L: Lit{V: 1}, R: Lit{V: 1}})
+	for i := 0; i < 100; i++ {
+		v, ok := e.RValue(e.Random(rnd, 10))
+		require.True(t, ok)
+		v.eval()
+	}
+}
+
+// testRand is an implementation of generator.Rand for testing purposes
+type testRand struct {
+	rnd *rand.Rand
+}
+
+func (r testRand) Fork(name string) generator.Rand {
+	return r
+}
+
+func (r testRand) HasMore() bool {
+	return false
+}
+
+func (r testRand) R() *rand.Rand {
+	return r.rnd
+}
+
+type Base struct {
+	ID int
+}
+
+type base struct {
+	Name  string
+	count int
+}
+
+type Entity struct {
+	Base
+	base
+	*Pair
+	secret string
+}
+
+func TestReflectionGenEmbedded(t *testing.T) {
+	g := generator.ReflectionGen[Entity](generator.ReflectionGenDefaultOpts())
+	values := geniterable.ToSlice(generator.EnumerateValues(g, 2))
+	require.Contains(t, values, Entity{Base: Base{ID: 1}, base: base{Name: "a"}, Pair: &Pair{A: "b", B: 1}})
+	for _, v := range values {
+		require.Equal(t, 0, v.count)
+		require.Equal(t, "", v.secret)
+	}
+}
+
+func TestReflectionGenUnexportedFields(t *testing.T) {
+	opts := generator.ReflectionGenDefaultOpts()
+	opts.GenerateUnexportedFields(true)
+	g := generator.ReflectionGen[Entity](opts)
+	values := geniterable.ToSlice(generator.EnumerateValues(g, 2))
+	require.Contains(t, values, Entity{Base: Base{ID: 1}, base: base{Name: "a", count: 1}, Pair: nil, secret: "b"})
+}
+
+func TestReflectionGenZeroFields(t *testing.T) {
+	opts := generator.ReflectionGenDefaultOpts()
+	opts.ZeroFields(Pair{}, "A")
+	g := generator.ReflectionGen[Pair](opts)
+	values := geniterable.ToSlice(generator.EnumerateValues(g, 2))
+	require.Equal(t, []Pair{{B: 0}, {B: 1}}, values)
+
+	require.PanicsWithError(t, "struct generator_test.Entity has no field Name", func() {
+		opts.ZeroFields(Entity{}, "Name")
+	})
+}
+
+type Level int8
+
+func TestReflectionGenNamedBasicTypes(t *testing.T) {
+	g := generator.ReflectionGen[Level](generator.ReflectionGenDefaultOpts())
+	values := geniterable.ToSlice(generator.EnumerateValues(g, 2))
+	require.Equal(t, []Level{0, 1}, values)
+}
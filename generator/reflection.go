@@ -1,11 +1,12 @@
 package generator
 
 import (
+	"errors"
 	"fmt"
-	"log"
 	"reflect"
+	"unsafe"
 
-	"github.com/peterzeller/go-fun/zero"
+	"github.com/peterzeller/go-fun/equality"
 )
 
 // ErrUnsupportedType is returned if a type is not supported by a ReflectionGenFun
@@ -18,6 +19,16 @@ type ReflectionGenFun func(t reflect.Type, opts *ReflectionGeneratorOptions) (Un
 // ReflectionGeneratorOptions contains options for reflection-based generators.
 type ReflectionGeneratorOptions struct {
 	generators []ReflectionGenFun
+	// implementations of interface types registered with RegisterImplementations
+	implementations map[reflect.Type][]reflect.Type
+	// recursive contains the generators of the recursive types that are currently being built
+	recursive map[reflect.Type]UntypedGenerator
+	// named generators registered with RegisterNamed
+	named map[string]namedGenerator
+	// unexportedFields is true if unexported struct fields are generated
+	unexportedFields bool
+	// zeroFields contains the fields that are left at their zero value, by struct type and field name
+	zeroFields map[reflect.Type]map[string]bool
 }
 
 // Register a generator in the options.
@@ -35,7 +46,6 @@ func (r *ReflectionGeneratorOptions) RegisterConstructor(constructorFun interfac
 	returnType := reflect.TypeOf(constructorFun).Out(0)
 	f := func(t reflect.Type, opts *ReflectionGeneratorOptions) (UntypedGenerator, error) {
 		if !returnType.AssignableTo(t) {
-			log.Printf("Constructor did not match: %v != %v", returnType, t)
 			return nil, ErrUnsupportedType
 		}
 		return g, nil
@@ -43,6 +53,76 @@ func (r *ReflectionGeneratorOptions) RegisterConstructor(constructorFun interfac
 	r.Register(f)
 }
 
+// RegisterImplementations registers the types that are generated for an interface type.
+// The interface type is given as a nil pointer, for example (*fmt.Stringer)(nil),
+// and the implementations as example values, for example Circle{} or &Square{}.
+func (r *ReflectionGeneratorOptions) RegisterImplementations(iface interface{}, implementations ...interface{}) {
+	ptrType := reflect.TypeOf(iface)
+	if ptrType == nil || ptrType.Kind() != reflect.Pointer || ptrType.Elem().Kind() != reflect.Interface {
+		panic(fmt.Errorf("iface must be a nil pointer to an interface type, but was %v", ptrType))
+	}
+	ifaceType := ptrType.Elem()
+	if len(implementations) == 0 {
+		panic(fmt.Errorf("no implementations given for %v", ifaceType))
+	}
+	impls := make([]reflect.Type, len(implementations))
+	for i, impl := range implementations {
+		implType := reflect.TypeOf(impl)
+		if implType == nil || !implType.Implements(ifaceType) {
+			panic(fmt.Errorf("%v does not implement %v", implType, ifaceType))
+		}
+		impls[i] = implType
+	}
+	if r.implementations == nil {
+		r.implementations = make(map[reflect.Type][]reflect.Type)
+	}
+	r.implementations[ifaceType] = impls
+	r.Register(func(t reflect.Type, opts *ReflectionGeneratorOptions) (UntypedGenerator, error) {
+		if t != ifaceType {
+			return nil, ErrUnsupportedType
+		}
+		gens := make([]Generator[interface{}, interface{}], len(impls))
+		for i, implType := range impls {
+			g, err := buildGenerator(opts, implType)
+			if err != nil {
+				return nil, fmt.Errorf("generating implementation %v of %v: %w", implType, ifaceType, err)
+			}
+			gens[i] = ToTypedGenerator[interface{}, interface{}](g)
+		}
+		return ToUntyped(OneOf(gens...)), nil
+	})
+}
+
+// GenerateUnexportedFields enables or disables the generation of unexported struct fields.
+// Unexported fields are set using the unsafe package and are left at their zero value by default.
+// Exported fields of embedded structs are always generated.
+func (r *ReflectionGeneratorOptions) GenerateUnexportedFields(enable bool) {
+	r.unexportedFields = enable
+}
+
+// ZeroFields leaves the given fields of a struct type at their zero value.
+// The struct type is given as an example value, for example ZeroFields(Account{}, "Cache").
+// Fields of embedded structs must be registered for the embedded struct type.
+// This is like the tag `gen:"skip"` for types that cannot be changed.
+func (r *ReflectionGeneratorOptions) ZeroFields(structValue interface{}, fields ...string) {
+	t := reflect.TypeOf(structValue)
+	if t == nil || t.Kind() != reflect.Struct {
+		panic(fmt.Errorf("structValue must be a struct, but was %v", t))
+	}
+	if r.zeroFields == nil {
+		r.zeroFields = make(map[reflect.Type]map[string]bool)
+	}
+	if r.zeroFields[t] == nil {
+		r.zeroFields[t] = make(map[string]bool)
+	}
+	for _, f := range fields {
+		if field, ok := t.FieldByName(f); !ok || len(field.Index) != 1 {
+			panic(fmt.Errorf("struct %v has no field %s", t, f))
+		}
+		r.zeroFields[t][f] = true
+	}
+}
+
 func (r *ReflectionGeneratorOptions) generatorFromConstructor(constructorFun interface{}) (UntypedGenerator, error) {
 	v := reflect.ValueOf(constructorFun)
 	t := reflect.TypeOf(constructorFun)
@@ -104,7 +184,8 @@ func (r *ReflectionGeneratorOptions) generatorFromConstructor(constructorFun int
 }
 
 // ReflectionGenDefaultOpts returns default options for reflection-based generators.
-// It contains generators for basic types, slices, maps, and structs.
+// It contains generators for basic types, slices, arrays, maps, pointers, and structs.
+// Interfaces are supported after registering their implementations with RegisterImplementations.
 func ReflectionGenDefaultOpts() *ReflectionGeneratorOptions {
 	r := &ReflectionGeneratorOptions{}
 	r.Register(reflectionGenBasicTypes)
@@ -112,9 +193,28 @@ func ReflectionGenDefaultOpts() *ReflectionGeneratorOptions {
 }
 
 // ReflectionGen uses reflection to return a generator for the generic type T.
-// To support custom types, pass in ReflectionGeneratorOptions with registered generators for those types.
+// Types implementing Arbitrary use their own generator.
+// To support other custom types, pass in ReflectionGeneratorOptions with registered generators for those types.
+// Recursive types like trees are supported if the recursion goes through a pointer, slice, map, or interface,
+// which provide the base cases.
+//
+// The generation of struct fields can be configured with the `gen` tag,
+// which is a comma separated list of options, for example `gen:"min=1,max=10"`:
+//
+//	skip or -         leave the field at its zero value
+//	min=N, max=N      range of a number field (inclusive)
+//	chars=a-z0-9_     characters of a string field, with ranges like a-z
+//	minlen=N          minimum length of a string (in runes), slice, array, or map
+//	maxlen=N          maximum length of a string (in runes), slice, array, or map
+//	oneof=a|b|c       one of the given values of a string, bool, or number field
+//	nonzero           never use the zero value: non-empty strings, slices, and maps, non-nil pointers,
+//	                  and numbers and bools other than 0 and false
+//	ref=name          use the generator registered with RegisterNamed (cannot be combined with other options)
+//
+// The generators for these options produce valid values directly, so they also work for small sizes.
+// Values cannot contain commas. Invalid tags cause a panic.
 func ReflectionGen[T any](opts *ReflectionGeneratorOptions) Generator[T, interface{}] {
-	typ := reflect.TypeOf(zero.Value[T]())
+	typ := reflect.TypeOf((*T)(nil)).Elem()
 	gen, err := buildGenerator(opts, typ)
 	if err != nil {
 		panic(err)
@@ -122,19 +222,127 @@ func ReflectionGen[T any](opts *ReflectionGeneratorOptions) Generator[T, interfa
 	return ToTypedGenerator[T, interface{}](gen)
 }
 
+// buildGenerator returns the generator for the given type.
+// Types implementing Arbitrary use their own generator, other types use the last registered generator that supports them.
 func buildGenerator(opts *ReflectionGeneratorOptions, typ reflect.Type) (UntypedGenerator, error) {
+	if self, ok := opts.recursive[typ]; ok {
+		return self, nil
+	}
+	if g, ok := arbitraryGenerator(typ); ok {
+		return g, nil
+	}
+	if opts.isRecursive(typ) {
+		return recursiveGenerator(opts, typ)
+	}
+	return buildRegistered(opts, typ)
+}
+
+func buildRegistered(opts *ReflectionGeneratorOptions, typ reflect.Type) (UntypedGenerator, error) {
 	for i := len(opts.generators) - 1; i >= 0; i-- {
 		genFunc := opts.generators[i]
 		gen, err := genFunc(typ, opts)
-		log.Printf("Trying generator %v for %v: %v", i, typ, err)
 		if err == nil {
 			return gen, nil
 		}
+		if !errors.Is(err, ErrUnsupportedType) {
+			return nil, err
+		}
 	}
 	return nil, fmt.Errorf("no generator found for type %s", typ)
 }
 
+// recursiveGenerator builds the generator for a type that contains itself.
+// It uses Recursive to limit the size of the values, and the references to typ use the recursive generator itself.
+func recursiveGenerator(opts *ReflectionGeneratorOptions, typ reflect.Type) (UntypedGenerator, error) {
+	build := func(self UntypedGenerator) (UntypedGenerator, error) {
+		return buildRegistered(opts.withRecursive(typ, self), typ)
+	}
+	// build once to report errors now instead of on first use
+	if _, err := build(ToUntyped(Empty[interface{}, interface{}]())); err != nil {
+		return nil, err
+	}
+	return ToUntyped(Recursive(func(self Generator[interface{}, interface{}]) Generator[interface{}, interface{}] {
+		g, err := build(ToUntyped(self))
+		if err != nil {
+			panic(err)
+		}
+		return ToTypedGenerator[interface{}, interface{}](g)
+	})), nil
+}
+
+// withRecursive returns a copy of the options in which typ is generated by self.
+func (r *ReflectionGeneratorOptions) withRecursive(typ reflect.Type, self UntypedGenerator) *ReflectionGeneratorOptions {
+	res := *r
+	res.recursive = make(map[reflect.Type]UntypedGenerator, len(r.recursive)+1)
+	for t, g := range r.recursive {
+		res.recursive[t] = g
+	}
+	res.recursive[typ] = self
+	return &res
+}
+
+// isRecursive returns true if typ contains itself without going through a recursive type that is currently being built.
+func (r *ReflectionGeneratorOptions) isRecursive(typ reflect.Type) bool {
+	visited := make(map[reflect.Type]bool)
+	var reaches func(t reflect.Type) bool
+	reaches = func(t reflect.Type) bool {
+		for _, c := range r.componentTypes(t) {
+			if c == typ {
+				return true
+			}
+			if _, ok := r.recursive[c]; ok || visited[c] {
+				continue
+			}
+			visited[c] = true
+			if reaches(c) {
+				return true
+			}
+		}
+		return false
+	}
+	return reaches(typ)
+}
+
+// componentTypes returns the types of the values that a value of type t is built from
+func (r *ReflectionGeneratorOptions) componentTypes(t reflect.Type) []reflect.Type {
+	if _, ok := arbitraryMethod(t); ok {
+		// the generator of the type does not use the components
+		return nil
+	}
+	switch t.Kind() {
+	case reflect.Array, reflect.Pointer, reflect.Slice:
+		return []reflect.Type{t.Elem()}
+	case reflect.Map:
+		return []reflect.Type{t.Key(), t.Elem()}
+	case reflect.Interface:
+		return r.implementations[t]
+	case reflect.Struct:
+		res := make([]reflect.Type, t.NumField())
+		for i := range res {
+			res[i] = t.Field(i).Type
+		}
+		return res
+	default:
+		return nil
+	}
+}
+
 func reflectionGenBasicTypes(t reflect.Type, opts *ReflectionGeneratorOptions) (UntypedGenerator, error) {
+	g, err := kindGenerator(t, opts)
+	if err != nil {
+		return nil, err
+	}
+	isBasic := t.Kind() <= reflect.Complex128 || t.Kind() == reflect.String
+	if isBasic && t.PkgPath() != "" {
+		// the generators of basic kinds generate the predeclared types, which are converted to named types like time.Duration
+		return ToUntyped(Map(ToTypedGenerator[interface{}, interface{}](g), func(v interface{}) interface{} {
+			return reflect.ValueOf(v).Convert(t).Interface()
+		})), nil
+	}
+	return g, nil
+}
+
+func kindGenerator(t reflect.Type, opts *ReflectionGeneratorOptions) (UntypedGenerator, error) {
 	switch t.Kind() {
 	case reflect.Invalid:
 		return nil, ErrUnsupportedType
@@ -163,15 +371,15 @@ func reflectionGenBasicTypes(t reflect.Type, opts *ReflectionGeneratorOptions) (
 	case reflect.Uintptr:
 		return ToUntyped(Uintptr()), nil
 	case reflect.Float32:
-		return nil, ErrUnsupportedType
+		return ToUntyped(Float32()), nil
 	case reflect.Float64:
-		return nil, ErrUnsupportedType
+		return ToUntyped(Float64()), nil
 	case reflect.Complex64:
-		return nil, ErrUnsupportedType
+		return ToUntyped(Complex64()), nil
 	case reflect.Complex128:
-		return nil, ErrUnsupportedType
+		return ToUntyped(Complex128()), nil
 	case reflect.Array:
-		return nil, ErrUnsupportedType
+		return arrayGenerator(t, opts)
 	case reflect.Chan:
 		return nil, ErrUnsupportedType
 	case reflect.Func:
@@ -179,11 +387,11 @@ func reflectionGenBasicTypes(t reflect.Type, opts *ReflectionGeneratorOptions) (
 	case reflect.Interface:
 		return nil, ErrUnsupportedType
 	case reflect.Map:
-		return nil, ErrUnsupportedType
+		return mapGenerator(t, opts, 0, -1)
 	case reflect.Pointer:
-		return nil, ErrUnsupportedType
+		return pointerGenerator(t, opts)
 	case reflect.Slice:
-		return sliceGenerator(t, opts)
+		return sliceGenerator(t, opts, 0, -1)
 	case reflect.String:
 		return ToUntyped(String()), nil
 	case reflect.Struct:
@@ -195,7 +403,8 @@ func reflectionGenBasicTypes(t reflect.Type, opts *ReflectionGeneratorOptions) (
 	}
 }
 
-func sliceGenerator(t reflect.Type, opts *ReflectionGeneratorOptions) (UntypedGenerator, error) {
+// sliceGenerator generates slices with a length between minLen and maxLen (see SliceLen).
+func sliceGenerator(t reflect.Type, opts *ReflectionGeneratorOptions, minLen, maxLen int) (UntypedGenerator, error) {
 	if t.Kind() != reflect.Slice {
 		return nil, fmt.Errorf("not a slice: %v", t)
 	}
@@ -206,7 +415,7 @@ func sliceGenerator(t reflect.Type, opts *ReflectionGeneratorOptions) (UntypedGe
 	if err != nil {
 		return nil, fmt.Errorf("generating element generator for %v slice: %w", elemType, err)
 	}
-	sliceGen := Map(Slice(ToTypedGenerator[interface{}, interface{}](elemGen)),
+	sliceGen := Map(SliceLen(ToTypedGenerator[interface{}, interface{}](elemGen), minLen, maxLen),
 		func(ar []interface{}) interface{} {
 			r := reflect.MakeSlice(t, len(ar), len(ar))
 			for i, v := range ar {
@@ -217,37 +426,154 @@ func sliceGenerator(t reflect.Type, opts *ReflectionGeneratorOptions) (UntypedGe
 	return ToUntyped(sliceGen), nil
 }
 
+func arrayGenerator(t reflect.Type, opts *ReflectionGeneratorOptions) (UntypedGenerator, error) {
+	elemType := t.Elem()
+	elemGen, err := buildGenerator(opts, elemType)
+	if err != nil {
+		return nil, fmt.Errorf("generating element generator for %v array: %w", elemType, err)
+	}
+	arrayGen := Map(SliceFixedLength(ToTypedGenerator[interface{}, interface{}](elemGen), t.Len()),
+		func(ar []interface{}) interface{} {
+			r := reflect.New(t).Elem()
+			for i, v := range ar {
+				r.Index(i).Set(reflect.ValueOf(v))
+			}
+			return r.Interface()
+		})
+	return ToUntyped(arrayGen), nil
+}
+
+// mapGenerator generates maps like DictMut, with a length between minLen and maxLen.
+// DictMut cannot be used directly, because the keys are only known to be comparable at runtime.
+func mapGenerator(t reflect.Type, opts *ReflectionGeneratorOptions, minLen, maxLen int) (UntypedGenerator, error) {
+	keyGen, err := buildGenerator(opts, t.Key())
+	if err != nil {
+		return nil, fmt.Errorf("generating key generator for %v: %w", t, err)
+	}
+	valueGen, err := buildGenerator(opts, t.Elem())
+	if err != nil {
+		return nil, fmt.Errorf("generating value generator for %v: %w", t, err)
+	}
+	var eq equality.Equality[interface{}] = equality.Fun[interface{}](func(a, b interface{}) bool {
+		return a == b
+	})
+	keys := SliceDistinctLen(ToTypedGenerator[interface{}, interface{}](keyGen), eq, minLen, maxLen)
+	mapGen := dictOf(keys, ToTypedGenerator[interface{}, interface{}](valueGen),
+		func(keys []interface{}, values []interface{}) interface{} {
+			m := reflect.MakeMapWithSize(t, len(keys))
+			for i, key := range keys {
+				m.SetMapIndex(reflect.ValueOf(key), reflect.ValueOf(values[i]))
+			}
+			return m.Interface()
+		})
+	return ToUntyped(mapGen), nil
+}
+
+// pointerGenerator generates nil or a pointer to a new value.
+// Nil is the first alternative, so that shrinking prefers it.
+func pointerGenerator(t reflect.Type, opts *ReflectionGeneratorOptions) (UntypedGenerator, error) {
+	ptrGen, err := nonNilPointerGenerator(t, opts)
+	if err != nil {
+		return nil, err
+	}
+	nilGen := Constant(reflect.Zero(t).Interface())
+	return ToUntyped(Frequency(Weighted(1, nilGen), Weighted(3, ptrGen))), nil
+}
+
+// nonNilPointerGenerator generates pointers to new values.
+func nonNilPointerGenerator(t reflect.Type, opts *ReflectionGeneratorOptions) (Generator[interface{}, interface{}], error) {
+	elemGen, err := buildGenerator(opts, t.Elem())
+	if err != nil {
+		return nil, fmt.Errorf("generating element generator for %v: %w", t, err)
+	}
+	return Map(ToTypedGenerator[interface{}, interface{}](elemGen), func(v interface{}) interface{} {
+		p := reflect.New(t.Elem())
+		p.Elem().Set(reflect.ValueOf(v))
+		return p.Interface()
+	}), nil
+}
+
+// structGenerator generates the fields of a struct, which can be configured with the `gen` tag (see genTag).
 func structGenerator(t reflect.Type, opts *ReflectionGeneratorOptions) (UntypedGenerator, error) {
-	fields := reflect.VisibleFields(t)
+	fields, fieldGens, err := structFieldGenerators(t, opts, nil)
+	if err != nil {
+		return nil, err
+	}
 	if len(fields) == 0 {
 		zeroVal := reflect.New(t).Elem()
-		return ToUntyped(Constant[interface{}](zeroVal)), nil
+		return ToUntyped(Constant(zeroVal.Interface())), nil
 	}
 
-	fieldGens := make([]UntypedGenerator, len(fields))
-	for i, field := range fields {
-		g, err := reflectionGenBasicTypes(field.Type, opts)
-		if err != nil {
-			return nil, fmt.Errorf("error creating generator for struct %s, field %s: %s", t.Name(), field.Name, err)
-		}
-		fieldGens[i] = g
-	}
-	g := Map(ToTypedGenerator[interface{}, interface{}](fieldGens[0]), func(value interface{}) []interface{} {
+	g := Map(fieldGens[0], func(value interface{}) []interface{} {
 		values := make([]interface{}, 1, len(fields))
 		values[0] = value
 		return values
 	})
 	for i := 1; i < len(fieldGens); i++ {
-		g = UntypedR(Zip(g, ToTypedGenerator[interface{}, interface{}](fieldGens[i]), func(ar []interface{}, v interface{}) []interface{} {
+		g = UntypedR(Zip(g, fieldGens[i], func(ar []interface{}, v interface{}) []interface{} {
 			return append(ar, v)
 		}))
 	}
 	sGen := Map(g, func(fieldValues []interface{}) interface{} {
 		val := reflect.New(t).Elem()
 		for i, v := range fieldValues {
-			val.Field(i).Set(reflect.ValueOf(v))
+			f := val.FieldByIndex(fields[i])
+			if !f.CanSet() {
+				// unexported field
+				f = reflect.NewAt(f.Type(), unsafe.Pointer(f.UnsafeAddr())).Elem()
+			}
+			f.Set(reflect.ValueOf(v))
 		}
 		return val.Interface()
 	})
 	return ToUntyped(sGen), nil
 }
+
+// structName returns the name of a struct type for error messages, which is the type literal for anonymous structs
+func structName(t reflect.Type) string {
+	if t.Name() == "" {
+		return t.String()
+	}
+	return t.Name()
+}
+
+// structFieldGenerators returns the indexes and generators of the fields of t that are generated.
+// Embedded structs are generated as a whole, unless they are unexported.
+// Then only their exported fields are generated, which can be set without unsafe.
+func structFieldGenerators(t reflect.Type, opts *ReflectionGeneratorOptions, prefix []int) ([][]int, []Generator[interface{}, interface{}], error) {
+	var fields [][]int
+	var fieldGens []Generator[interface{}, interface{}]
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		index := append(append([]int{}, prefix...), i)
+		tagStr := field.Tag.Get("gen")
+		tag, err := parseGenTag(tagStr)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid tag gen:%q on field %s of struct %s: %w", tagStr, field.Name, structName(t), err)
+		}
+		if tag.skip || opts.zeroFields[t][field.Name] {
+			continue
+		}
+		if !field.IsExported() && !opts.unexportedFields {
+			if field.Anonymous && field.Type.Kind() == reflect.Struct && tagStr == "" {
+				embeddedFields, embeddedGens, err := structFieldGenerators(field.Type, opts, index)
+				if err != nil {
+					return nil, nil, err
+				}
+				fields = append(fields, embeddedFields...)
+				fieldGens = append(fieldGens, embeddedGens...)
+			}
+			continue
+		}
+		g, err := tag.generator(opts, field.Type)
+		if err != nil {
+			if tagStr != "" {
+				return nil, nil, fmt.Errorf("invalid tag gen:%q on field %s of struct %s: %w", tagStr, field.Name, structName(t), err)
+			}
+			return nil, nil, fmt.Errorf("error creating generator for struct %s, field %s: %w", t.Name(), field.Name, err)
+		}
+		fields = append(fields, index)
+		fieldGens = append(fieldGens, g)
+	}
+	return fields, fieldGens, nil
+}
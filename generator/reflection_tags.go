@@ -0,0 +1,379 @@
+package generator
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// RegisterNamed registers a generator under a name, so that struct fields can use it with the tag `gen:"ref=name"`.
+func RegisterNamed[T, R any](opts *ReflectionGeneratorOptions, name string, gen Generator[T, R]) {
+	if opts.named == nil {
+		opts.named = make(map[string]namedGenerator)
+	}
+	opts.named[name] = namedGenerator{
+		typ: reflect.TypeOf((*T)(nil)).Elem(),
+		gen: ToUntyped(gen),
+	}
+}
+
+type namedGenerator struct {
+	typ reflect.Type
+	gen UntypedGenerator
+}
+
+// genTag is the parsed `gen` tag of a struct field (see ReflectionGen for the syntax).
+type genTag struct {
+	skip     bool
+	min, max string
+	chars    []rune
+	minLen   int
+	maxLen   int
+	oneOf    []string
+	nonZero  bool
+	ref      string
+}
+
+func parseGenTag(tag string) (genTag, error) {
+	res := genTag{minLen: -1, maxLen: -1}
+	if tag == "" {
+		return res, nil
+	}
+	if tag == "-" {
+		res.skip = true
+		return res, nil
+	}
+	seen := make(map[string]bool)
+	for _, option := range strings.Split(tag, ",") {
+		key, value, hasValue := strings.Cut(strings.TrimSpace(option), "=")
+		if seen[key] {
+			return res, fmt.Errorf("option %s is given twice", key)
+		}
+		seen[key] = true
+		needsValue := key != "skip" && key != "nonzero"
+		if hasValue != needsValue {
+			if needsValue {
+				return res, fmt.Errorf("option %s needs a value (%s=...)", key, key)
+			}
+			return res, fmt.Errorf("option %s has no value", key)
+		}
+		var err error
+		switch key {
+		case "skip":
+			res.skip = true
+		case "nonzero":
+			res.nonZero = true
+		case "min":
+			res.min = value
+		case "max":
+			res.max = value
+		case "chars":
+			res.chars, err = parseChars(value)
+		case "minlen":
+			res.minLen, err = parseLength(value)
+		case "maxlen":
+			res.maxLen, err = parseLength(value)
+		case "oneof":
+			res.oneOf = strings.Split(value, "|")
+		case "ref":
+			res.ref = value
+		case "":
+			return res, fmt.Errorf("empty option")
+		default:
+			return res, fmt.Errorf("unknown option %s", key)
+		}
+		if err != nil {
+			return res, fmt.Errorf("option %s: %w", key, err)
+		}
+	}
+	if res.skip && len(seen) > 1 {
+		return res, fmt.Errorf("skip cannot be combined with other options")
+	}
+	if res.minLen >= 0 && res.maxLen >= 0 && res.minLen > res.maxLen {
+		return res, fmt.Errorf("minlen %d is greater than maxlen %d", res.minLen, res.maxLen)
+	}
+	if res.ref != "" && len(seen) > 1 {
+		return res, fmt.Errorf("ref cannot be combined with other options")
+	}
+	if res.oneOf != nil {
+		if res.min != "" || res.max != "" || res.chars != nil {
+			return res, fmt.Errorf("oneof cannot be combined with min, max, or chars")
+		}
+	}
+	return res, nil
+}
+
+// parseChars parses a character set like "a-z0-9_".
+// A '-' at the start or the end is a normal character.
+func parseChars(s string) ([]rune, error) {
+	runes := []rune(s)
+	if len(runes) == 0 {
+		return nil, fmt.Errorf("empty character set")
+	}
+	var res []rune
+	for i := 0; i < len(runes); i++ {
+		if i+2 < len(runes) && runes[i+1] == '-' {
+			from, to := runes[i], runes[i+2]
+			if from > to {
+				return nil, fmt.Errorf("invalid character range %c-%c", from, to)
+			}
+			for r := from; r <= to; r++ {
+				res = append(res, r)
+			}
+			i += 2
+			continue
+		}
+		res = append(res, runes[i])
+	}
+	return res, nil
+}
+
+func parseLength(s string) (int, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid length %q", s)
+	}
+	return n, nil
+}
+
+// generator returns the generator for a field of type typ with this tag.
+// The options are built into the generators instead of filtering the generated values,
+// because a filter does not find valid values for small sizes.
+func (tag genTag) generator(opts *ReflectionGeneratorOptions, typ reflect.Type) (Generator[interface{}, interface{}], error) {
+	minLen, maxLen := tag.minLen, tag.maxLen
+	if minLen >= 0 || maxLen >= 0 {
+		switch typ.Kind() {
+		case reflect.String, reflect.Slice, reflect.Map:
+		case reflect.Array:
+			if !inLengthBounds(typ.Len(), minLen, maxLen) {
+				return nil, fmt.Errorf("length %d of array type %v is not between minlen and maxlen", typ.Len(), typ)
+			}
+		default:
+			return nil, fmt.Errorf("minlen and maxlen can only be used for strings, slices, arrays, and maps, but type is %v", typ)
+		}
+	}
+	if minLen < 0 {
+		minLen = 0
+	}
+	hasLength := typ.Kind() == reflect.String || typ.Kind() == reflect.Slice || typ.Kind() == reflect.Map
+	if tag.nonZero && hasLength && minLen == 0 {
+		if maxLen == 0 {
+			return nil, fmt.Errorf("nonzero cannot be combined with maxlen 0")
+		}
+		minLen = 1
+	}
+	switch {
+	case tag.ref != "":
+		named, ok := opts.named[tag.ref]
+		if !ok {
+			return nil, fmt.Errorf("no generator registered with name %q", tag.ref)
+		}
+		if !named.typ.AssignableTo(typ) {
+			return nil, fmt.Errorf("generator %q generates values of type %v, which cannot be used for type %v", tag.ref, named.typ, typ)
+		}
+		return ToTypedGenerator[interface{}, interface{}](named.gen), nil
+	case tag.oneOf != nil:
+		gens := make([]Generator[interface{}, interface{}], len(tag.oneOf))
+		for i, s := range tag.oneOf {
+			v, err := parseValue(s, typ)
+			if err != nil {
+				return nil, fmt.Errorf("oneof: %w", err)
+			}
+			if tag.nonZero && reflect.ValueOf(v).IsZero() {
+				return nil, fmt.Errorf("oneof: value %q is the zero value, but the field is nonzero", s)
+			}
+			if hasLength && !inLengthBounds(length(reflect.ValueOf(v)), minLen, maxLen) {
+				return nil, fmt.Errorf("oneof: length of value %q is not between minlen and maxlen", s)
+			}
+			gens[i] = Constant(v)
+		}
+		return UntypedR(OneOf(gens...)), nil
+	case tag.min != "" || tag.max != "":
+		return numberRangeGenerator(typ, tag.min, tag.max, tag.nonZero)
+	case tag.chars != nil:
+		if typ.Kind() != reflect.String {
+			return nil, fmt.Errorf("chars can only be used for strings, but type is %v", typ)
+		}
+		return stringGenerator(typ, minLen, maxLen, tag.chars), nil
+	case hasLength && (minLen > 0 || maxLen >= 0):
+		return lengthGenerator(opts, typ, minLen, maxLen)
+	case tag.nonZero:
+		return nonZeroGenerator(opts, typ)
+	default:
+		u, err := buildGenerator(opts, typ)
+		if err != nil {
+			return nil, err
+		}
+		return ToTypedGenerator[interface{}, interface{}](u), nil
+	}
+}
+
+// stringGenerator generates strings of type typ with a length between minLen and maxLen.
+func stringGenerator(typ reflect.Type, minLen, maxLen int, chars []rune) Generator[interface{}, interface{}] {
+	return UntypedR(Map(StringLen(minLen, maxLen, chars...), func(s string) interface{} {
+		return reflect.ValueOf(s).Convert(typ).Interface()
+	}))
+}
+
+// lengthGenerator generates strings, slices, or maps of type typ with a length between minLen and maxLen.
+func lengthGenerator(opts *ReflectionGeneratorOptions, typ reflect.Type, minLen, maxLen int) (Generator[interface{}, interface{}], error) {
+	var g UntypedGenerator
+	var err error
+	switch typ.Kind() {
+	case reflect.String:
+		return stringGenerator(typ, minLen, maxLen, nil), nil
+	case reflect.Slice:
+		g, err = sliceGenerator(typ, opts, minLen, maxLen)
+	default:
+		g, err = mapGenerator(typ, opts, minLen, maxLen)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ToTypedGenerator[interface{}, interface{}](g), nil
+}
+
+// nonZeroGenerator generates values of type typ other than the zero value.
+// Strings, slices, and maps are handled by lengthGenerator.
+func nonZeroGenerator(opts *ReflectionGeneratorOptions, typ reflect.Type) (Generator[interface{}, interface{}], error) {
+	switch typ.Kind() {
+	case reflect.Bool:
+		return UntypedR(Constant(reflect.ValueOf(true).Convert(typ).Interface())), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return numberRangeGenerator(typ, "", "", true)
+	case reflect.Pointer:
+		return nonNilPointerGenerator(typ, opts)
+	default:
+		return nil, fmt.Errorf("nonzero can only be used for strings, slices, maps, pointers, bools, and numbers, but type is %v", typ)
+	}
+}
+
+// length returns the length of a string in runes and the length of other values with a length
+func length(v reflect.Value) int {
+	if v.Kind() == reflect.String {
+		return utf8.RuneCountInString(v.String())
+	}
+	return v.Len()
+}
+
+// parseValue parses a value of a oneof option
+func parseValue(s string, typ reflect.Type) (interface{}, error) {
+	var v interface{}
+	var err error
+	switch typ.Kind() {
+	case reflect.String:
+		v = s
+	case reflect.Bool:
+		v, err = strconv.ParseBool(s)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v, err = strconv.ParseInt(s, 0, typ.Bits())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		v, err = strconv.ParseUint(s, 0, typ.Bits())
+	case reflect.Float32, reflect.Float64:
+		v, err = strconv.ParseFloat(s, typ.Bits())
+	default:
+		return nil, fmt.Errorf("values of type %v are not supported", typ)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid value %q for type %v", s, typ)
+	}
+	return reflect.ValueOf(v).Convert(typ).Interface(), nil
+}
+
+// numberRangeGenerator returns a generator for numbers of type typ between min and max.
+// An empty bound is the smallest or largest value of the type.
+// If nonZero is set, the generated zeros are replaced with the number closest to 1 in the range.
+func numberRangeGenerator(typ reflect.Type, min, max string, nonZero bool) (Generator[interface{}, interface{}], error) {
+	convert := func(v interface{}) interface{} {
+		return reflect.ValueOf(v).Convert(typ).Interface()
+	}
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		hi := int64(math.MaxInt64 >> (64 - typ.Bits()))
+		lo := -hi - 1
+		if err := parseBound(min, &lo, func(s string) (int64, error) { return strconv.ParseInt(s, 0, typ.Bits()) }); err != nil {
+			return nil, err
+		}
+		if err := parseBound(max, &hi, func(s string) (int64, error) { return strconv.ParseInt(s, 0, typ.Bits()) }); err != nil {
+			return nil, err
+		}
+		if lo > hi {
+			return nil, fmt.Errorf("min %d is greater than max %d", lo, hi)
+		}
+		if nonZero && lo == 0 && hi == 0 {
+			return nil, fmt.Errorf("there is no nonzero value between min 0 and max 0")
+		}
+		return UntypedR(Map(Int64Range(lo, hi), func(i int64) interface{} {
+			if nonZero && i == 0 {
+				i = 1
+				if hi < 1 {
+					i = -1
+				}
+			}
+			return convert(i)
+		})), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		lo, hi := uint64(0), uint64(math.MaxUint64>>(64-typ.Bits()))
+		if err := parseBound(min, &lo, func(s string) (uint64, error) { return strconv.ParseUint(s, 0, typ.Bits()) }); err != nil {
+			return nil, err
+		}
+		if err := parseBound(max, &hi, func(s string) (uint64, error) { return strconv.ParseUint(s, 0, typ.Bits()) }); err != nil {
+			return nil, err
+		}
+		if lo > hi {
+			return nil, fmt.Errorf("min %d is greater than max %d", lo, hi)
+		}
+		if nonZero && hi == 0 {
+			return nil, fmt.Errorf("there is no nonzero value between min 0 and max 0")
+		}
+		return UntypedR(Map(UInt64Range(lo, hi), func(i uint64) interface{} {
+			if nonZero && i == 0 {
+				i = 1
+			}
+			return convert(i)
+		})), nil
+	case reflect.Float32, reflect.Float64:
+		lo, hi := math.Inf(-1), math.Inf(1)
+		if err := parseBound(min, &lo, func(s string) (float64, error) { return strconv.ParseFloat(s, typ.Bits()) }); err != nil {
+			return nil, err
+		}
+		if err := parseBound(max, &hi, func(s string) (float64, error) { return strconv.ParseFloat(s, typ.Bits()) }); err != nil {
+			return nil, err
+		}
+		if !(lo <= hi) {
+			return nil, fmt.Errorf("min %v is greater than max %v", lo, hi)
+		}
+		if nonZero && lo == 0 && hi == 0 {
+			return nil, fmt.Errorf("there is no nonzero value between min 0 and max 0")
+		}
+		return UntypedR(Map(Float64Range(lo, hi), func(f float64) interface{} {
+			if nonZero && f == 0 {
+				if hi > 0 {
+					f = math.Min(1, hi)
+				} else {
+					f = math.Max(-1, lo)
+				}
+			}
+			return convert(f)
+		})), nil
+	default:
+		return nil, fmt.Errorf("min and max can only be used for numbers, but type is %v", typ)
+	}
+}
+
+func parseBound[N any](s string, bound *N, parse func(s string) (N, error)) error {
+	if s == "" {
+		return nil
+	}
+	n, err := parse(s)
+	if err != nil {
+		return fmt.Errorf("invalid bound %q", s)
+	}
+	*bound = n
+	return nil
+}
@@ -0,0 +1,203 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/peterzeller/go-stateful-test/generator/geniterable"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseChars(t *testing.T) {
+	chars, err := parseChars("a-c_0-2-")
+	require.NoError(t, err)
+	require.Equal(t, "abc_012-", string(chars))
+
+	_, err = parseChars("z-a")
+	require.EqualError(t, err, "invalid character range z-a")
+}
+
+type Account struct {
+	ID      uint8   `gen:"min=1,max=10"`
+	Balance float64 `gen:"min=-100,max=100"`
+	Hex     string  `gen:"chars=0-9a-f,minlen=2,maxlen=4"`
+	Kind    string  `gen:"oneof=checking|savings"`
+	Tags    []int   `gen:"nonzero,maxlen=2"`
+	Owner   string  `gen:"ref=owner"`
+	Cache   []byte  `gen:"-"`
+	Note    string  `gen:"skip"`
+}
+
+func TestStructTags(t *testing.T) {
+	opts := ReflectionGenDefaultOpts()
+	RegisterNamed(opts, "owner", OneConstantOf("alice", "bob"))
+	g := ReflectionGen[Account](opts)
+	check := func(a Account) {
+		require.True(t, 1 <= a.ID && a.ID <= 10, "%+v", a)
+		require.True(t, -100 <= a.Balance && a.Balance <= 100, "%+v", a)
+		require.Regexp(t, "^[0-9a-f]{2,4}$", a.Hex)
+		require.Contains(t, []string{"checking", "savings"}, a.Kind)
+		require.NotNil(t, a.Tags)
+		require.LessOrEqual(t, len(a.Tags), 2)
+		require.Contains(t, []string{"alice", "bob"}, a.Owner)
+		require.Nil(t, a.Cache)
+		require.Equal(t, "", a.Note)
+	}
+	rnd := newTestRand(1)
+	for i := 0; i < 100; i++ {
+		a, ok := g.RValue(g.Random(rnd, 10))
+		require.True(t, ok)
+		check(a)
+	}
+	values := geniterable.ToSlice(EnumerateValues(g, 2))
+	require.NotEmpty(t, values)
+	for _, a := range values {
+		check(a)
+	}
+}
+
+type Level int
+
+func TestStructTagsNamedTypes(t *testing.T) {
+	type S struct {
+		L    Level  `gen:"oneof=1|3"`
+		Name []rune `gen:"minlen=1"`
+	}
+	g := ReflectionGen[S](ReflectionGenDefaultOpts())
+	values := geniterable.ToSlice(EnumerateValues(g, 2))
+	for _, s := range values {
+		require.Contains(t, []Level{1, 3}, s.L)
+		require.NotEmpty(t, s.Name)
+	}
+	require.Equal(t, 1, utf8.RuneCountInString(string(values[0].Name)))
+}
+
+func TestStructTagErrors(t *testing.T) {
+	tests := []struct {
+		gen      func()
+		expected string
+	}{
+		{func() {
+			ReflectionGen[struct {
+				X int `gen:"min=a"`
+			}](ReflectionGenDefaultOpts())
+		}, `invalid bound "a"`},
+		{func() {
+			ReflectionGen[struct {
+				X int8 `gen:"max=200"`
+			}](ReflectionGenDefaultOpts())
+		}, `invalid bound "200"`},
+		{func() {
+			ReflectionGen[struct {
+				X int `gen:"min=5,max=1"`
+			}](ReflectionGenDefaultOpts())
+		}, `min 5 is greater than max 1`},
+		{func() {
+			ReflectionGen[struct {
+				X string `gen:"min=1"`
+			}](ReflectionGenDefaultOpts())
+		}, `min and max can only be used for numbers, but type is string`},
+		{func() {
+			ReflectionGen[struct {
+				X int `gen:"size=3"`
+			}](ReflectionGenDefaultOpts())
+		}, `unknown option size`},
+		{func() {
+			ReflectionGen[struct {
+				X int `gen:"nonzero=1"`
+			}](ReflectionGenDefaultOpts())
+		}, `option nonzero has no value`},
+		{func() {
+			ReflectionGen[struct {
+				X int `gen:"skip,nonzero"`
+			}](ReflectionGenDefaultOpts())
+		}, `skip cannot be combined with other options`},
+		{func() {
+			ReflectionGen[struct {
+				X int `gen:"oneof=1|x"`
+			}](ReflectionGenDefaultOpts())
+		}, `oneof: invalid value "x" for type int`},
+		{func() {
+			ReflectionGen[struct {
+				X int `gen:"chars=abc"`
+			}](ReflectionGenDefaultOpts())
+		}, `chars can only be used for strings, but type is int`},
+		{func() {
+			ReflectionGen[struct {
+				X int `gen:"maxlen=3"`
+			}](ReflectionGenDefaultOpts())
+		}, `minlen and maxlen can only be used for strings, slices, arrays, and maps, but type is int`},
+		{func() {
+			ReflectionGen[struct {
+				X string `gen:"minlen=3,maxlen=1"`
+			}](ReflectionGenDefaultOpts())
+		}, `minlen 3 is greater than maxlen 1`},
+		{func() {
+			ReflectionGen[struct {
+				X string `gen:"ref=email"`
+			}](ReflectionGenDefaultOpts())
+		}, `no generator registered with name "email"`},
+		{func() {
+			opts := ReflectionGenDefaultOpts()
+			RegisterNamed(opts, "email", Int())
+			ReflectionGen[struct {
+				X string `gen:"ref=email"`
+			}](opts)
+		}, `generator "email" generates values of type int, which cannot be used for type string`},
+		{func() {
+			ReflectionGen[struct {
+				X int `gen:"ref=email,nonzero"`
+			}](ReflectionGenDefaultOpts())
+		}, `ref cannot be combined with other options`},
+		{func() {
+			ReflectionGen[struct {
+				X int `gen:"oneof=0|1,nonzero"`
+			}](ReflectionGenDefaultOpts())
+		}, `oneof: value "0" is the zero value, but the field is nonzero`},
+		{func() {
+			ReflectionGen[struct {
+				X string `gen:"oneof=a|abc,maxlen=2"`
+			}](ReflectionGenDefaultOpts())
+		}, `oneof: length of value "abc" is not between minlen and maxlen`},
+		{func() {
+			ReflectionGen[struct {
+				X uint `gen:"max=0,nonzero"`
+			}](ReflectionGenDefaultOpts())
+		}, `there is no nonzero value between min 0 and max 0`},
+		{func() {
+			ReflectionGen[struct {
+				X [3]int `gen:"minlen=4"`
+			}](ReflectionGenDefaultOpts())
+		}, `length 3 of array type [3]int is not between minlen and maxlen`},
+		{func() {
+			ReflectionGen[struct {
+				X struct{ Y int } `gen:"nonzero"`
+			}](ReflectionGenDefaultOpts())
+		}, `nonzero can only be used for strings, slices, maps, pointers, bools, and numbers, but type is struct { Y int }`},
+	}
+	for _, test := range tests {
+		err := panicError(test.gen)
+		require.Error(t, err)
+		require.Regexp(t, `^invalid tag gen:".*" on field X of struct struct \{.*\}: `, err.Error())
+		require.True(t, strings.HasSuffix(err.Error(), ": "+test.expected), "unexpected error: %v", err)
+	}
+}
+
+func TestStructTagErrorNamedStruct(t *testing.T) {
+	type Named struct {
+		X int `gen:"min=a"`
+	}
+	require.PanicsWithError(t, `invalid tag gen:"min=a" on field X of struct Named: invalid bound "a"`, func() {
+		ReflectionGen[Named](ReflectionGenDefaultOpts())
+	})
+}
+
+// panicError returns the error that f panics with
+func panicError(f func()) (err error) {
+	defer func() {
+		err, _ = recover().(error)
+	}()
+	f()
+	return nil
+}
@@ -63,8 +63,11 @@ func enumerateSets[T any](elems *linked.List[T], h hash.EqHash[T]) geniterable.I
 
 // Random implements Generator
 func (s *setGenerator[T, RT]) Random(rnd Rand, size int) hashset.Set[RT] {
-	n := rnd.R().Intn(size)
 	set := hashset.New(s.rvHash())
+	if size <= 0 {
+		return set
+	}
+	n := Choose(rnd, size)
 	for i := 0; i < n; i++ {
 		set = set.Add(s.gen.Random(rnd, size))
 	}
@@ -14,17 +14,30 @@ import (
 
 // Slice is a generator for slices.
 func Slice[T, TR any](elemGen Generator[T, TR]) Generator[[]T, []TR] {
+	return SliceLen(elemGen, 0, -1)
+}
+
+// SliceLen is a generator for slices with a length between minLen and maxLen.
+// A negative maxLen means that the length is not bounded.
+func SliceLen[T, TR any](elemGen Generator[T, TR], minLen, maxLen int) Generator[[]T, []TR] {
+	checkLengthBounds(minLen, maxLen)
 	return &sliceGen[T, TR]{
 		elemGen: elemGen,
+		minLen:  minLen,
+		maxLen:  maxLen,
 	}
 }
 
 type sliceGen[T, TR any] struct {
 	elemGen Generator[T, TR]
+	minLen  int
+	maxLen  int
 }
 
 func (s *sliceGen[T, TR]) Enumerate(depth int) geniterable.Iterable[[]TR] {
-	return geniterable.NonExhaustive(EnumerateSlices(depth, depth, s.elemGen))
+	return enumerateSlicesWithLength(s.minLen, s.maxLen, depth, func(length int) geniterable.Iterable[[]TR] {
+		return EnumerateSlices(length, depth, s.elemGen)
+	})
 }
 
 func EnumerateSlices[T, TR any](length, depth int, elemGen Generator[T, TR]) geniterable.Iterable[[]TR] {
@@ -64,29 +77,33 @@ func (s *sliceGen[T, TR]) RValue(elem []TR) ([]T, bool) {
 			return nil, false
 		}
 	}
-	return res, true
+	return res, inLengthBounds(len(res), s.minLen, s.maxLen)
 }
 
 func (s *sliceGen[T, TR]) Random(rnd Rand, size int) []TR {
-	if size <= 0 {
-		return []TR{}
+	l := s.minLen
+	if span := lengthSpan(s.minLen, s.maxLen, size); span > 0 {
+		l += Choose(rnd, span)
 	}
-	l := rnd.R().Intn(size)
 	res := make([]TR, l)
 	for i := range res {
-		res[i] = s.elemGen.Random(rnd, size-1)
+		res[i] = s.elemGen.Random(rnd, elementSize(size, 0))
 	}
 	return res
 }
 
 func (s *sliceGen[T, TR]) Shrink(elem []TR) iterable.Iterable[[]TR] {
-	rvs := elem
-	return iterable.Map(
-		shrink.ShrinkList(
-			linked.New(rvs...),
-			func(rv TR) iterable.Iterable[TR] {
-				return s.elemGen.Shrink(rv)
-			}),
+	return shrinkSlice(elem, s.minLen, s.elemGen.Shrink)
+}
+
+// shrinkSlice shrinks the elements of a slice and removes elements, keeping at least minLen elements.
+func shrinkSlice[TR any](elem []TR, minLen int, shrinkElem func(rv TR) iterable.Iterable[TR]) iterable.Iterable[[]TR] {
+	shrinks := iterable.Filter(
+		shrink.ShrinkList(linked.New(elem...), shrinkElem),
+		func(l *linked.List[TR]) bool {
+			return l.Length() >= minLen
+		})
+	return iterable.Map(shrinks,
 		func(l *linked.List[TR]) []TR {
 			return l.ToSlice()
 		})
@@ -103,20 +120,34 @@ func (s *sliceGen[T, TR]) Size(t []TR) *big.Int {
 
 // SliceDistinct generates slices with distinct elements.
 func SliceDistinct[T, TR any](elemGen Generator[T, TR], eq equality.Equality[T]) Generator[[]T, []TR] {
+	return SliceDistinctLen(elemGen, eq, 0, -1)
+}
+
+// SliceDistinctLen generates slices with distinct elements and a length between minLen and maxLen.
+// A negative maxLen means that the length is not bounded.
+// If the element generator does not produce minLen distinct values, the generator fails.
+func SliceDistinctLen[T, TR any](elemGen Generator[T, TR], eq equality.Equality[T], minLen, maxLen int) Generator[[]T, []TR] {
+	checkLengthBounds(minLen, maxLen)
 	return &sliceDistinctGen[T, TR]{
 		elemGen: elemGen,
 		eq:      eq,
+		minLen:  minLen,
+		maxLen:  maxLen,
 	}
 }
 
 type sliceDistinctGen[T, TR any] struct {
 	elemGen Generator[T, TR]
 	eq      equality.Equality[T]
+	minLen  int
+	maxLen  int
 }
 
 func (s *sliceDistinctGen[T, TR]) Enumerate(depth int) geniterable.Iterable[[]TR] {
-	// non-exhaustive, because there is no limit on length
-	return geniterable.NonExhaustive(EnumerateSlicesDistinct(depth, depth, s.elemGen, eqRandomValue(s.elemGen.RValue, s.eq)))
+	eq := eqRandomValue(s.elemGen.RValue, s.eq)
+	return enumerateSlicesWithLength(s.minLen, s.maxLen, depth, func(length int) geniterable.Iterable[[]TR] {
+		return EnumerateSlicesDistinct(length, depth, s.elemGen, eq)
+	})
 }
 
 func EnumerateSlicesDistinct[T, TR any](length, depth int, elemGen Generator[T, TR], eq equality.Equality[TR]) geniterable.Iterable[[]TR] {
@@ -161,18 +192,27 @@ func (s *sliceDistinctGen[T, TR]) RValue(elem []TR) ([]T, bool) {
 			return nil, false
 		}
 	}
-	return res, true
+	return res, inLengthBounds(len(res), s.minLen, s.maxLen)
 }
 
+// maxDistinctAttempts is the number of additional elements that SliceDistinctLen generates to reach the minimum length.
+const maxDistinctAttempts = 100
+
 func (s *sliceDistinctGen[T, TR]) Random(rnd Rand, size int) []TR {
-	if size <= 0 {
-		return []TR{}
+	l := s.minLen
+	if span := lengthSpan(s.minLen, s.maxLen, size); span > 0 {
+		l += Choose(rnd, span)
 	}
-	l := rnd.R().Intn(size)
 	res := make([]TR, 0, l)
 	resValues := make([]T, 0, l)
-	for i := 0; i < l; i++ {
-		vr := s.elemGen.Random(rnd, size-1)
+	// duplicates are dropped, so there can be more attempts to reach the minimum length
+	for i := 0; i < l || len(res) < s.minLen && i < l+maxDistinctAttempts; i++ {
+		// use larger sizes for the additional attempts, so that there are enough distinct values
+		extra := 0
+		if i >= l {
+			extra = i - l + 1
+		}
+		vr := s.elemGen.Random(rnd, elementSize(size, extra))
 		v, ok := s.elemGen.RValue(vr)
 		if ok && !slice.ContainsEq(resValues, v, s.eq) {
 			res = append(res, vr)
@@ -183,16 +223,7 @@ func (s *sliceDistinctGen[T, TR]) Random(rnd Rand, size int) []TR {
 }
 
 func (s *sliceDistinctGen[T, TR]) Shrink(elem []TR) iterable.Iterable[[]TR] {
-	rvs := elem
-	return iterable.Map(
-		shrink.ShrinkList(
-			linked.New(rvs...),
-			func(rv TR) iterable.Iterable[TR] {
-				return s.elemGen.Shrink(rv)
-			}),
-		func(l *linked.List[TR]) []TR {
-			return l.ToSlice()
-		})
+	return shrinkSlice(elem, s.minLen, s.elemGen.Shrink)
 }
 
 func (s *sliceDistinctGen[T, TR]) Size(t []TR) *big.Int {
@@ -225,3 +256,71 @@ func SliceFixedLength[T, TR any](elemGen Generator[T, TR], length int) Generator
 		return append([]T{e}, es...)
 	}))
 }
+
+// checkLengthBounds panics if minLen and maxLen are not valid length bounds.
+func checkLengthBounds(minLen, maxLen int) {
+	if minLen < 0 || maxLen >= 0 && minLen > maxLen {
+		panic(fmt.Errorf("invalid length bounds: minLen = %d, maxLen = %d", minLen, maxLen))
+	}
+}
+
+// inLengthBounds checks that a length is between minLen and maxLen (negative maxLen for unbounded)
+func inLengthBounds(length, minLen, maxLen int) bool {
+	return length >= minLen && (maxLen < 0 || length <= maxLen)
+}
+
+// lengthSpan returns the number of lengths starting from minLen that can be generated with the given size.
+// The size is the number of lengths for unbounded generators.
+func lengthSpan(minLen, maxLen, size int) int {
+	if maxLen >= 0 && maxLen-minLen+1 < size {
+		return maxLen - minLen + 1
+	}
+	return size
+}
+
+// elementSize is the size for the elements of a collection generated with the given size.
+func elementSize(size, extra int) int {
+	if size <= 0 {
+		return extra
+	}
+	return size - 1 + extra
+}
+
+// lengthLimit returns the maximum length to enumerate for the given depth and whether all lengths up to maxLen are enumerated.
+// Lengths up to the depth are enumerated, but at least minLen.
+func lengthLimit(minLen, maxLen, depth int) (int, bool) {
+	limit := depth
+	if limit < minLen {
+		limit = minLen
+	}
+	if maxLen >= 0 && limit >= maxLen {
+		return maxLen, true
+	}
+	return limit, false
+}
+
+// enumerateLengths enumerates the lengths for strings or slices with the given bounds up to the given depth.
+func enumerateLengths(minLen, maxLen, depth int) geniterable.Iterable[int] {
+	limit, exhaustive := lengthLimit(minLen, maxLen, depth)
+	lengths := geniterable.RangeI(minLen, limit)
+	if !exhaustive {
+		return geniterable.NonExhaustive(lengths)
+	}
+	return lengths
+}
+
+// enumerateSlicesWithLength enumerates the slices with the given length bounds.
+// The function enumerate returns the slices up to a given length.
+func enumerateSlicesWithLength[TR any](minLen, maxLen, depth int, enumerate func(length int) geniterable.Iterable[[]TR]) geniterable.Iterable[[]TR] {
+	limit, exhaustive := lengthLimit(minLen, maxLen, depth)
+	slices := enumerate(limit)
+	if minLen > 0 {
+		slices = geniterable.Filter(slices, func(s []TR) bool {
+			return len(s) >= minLen
+		})
+	}
+	if !exhaustive {
+		return geniterable.NonExhaustive(slices)
+	}
+	return slices
+}
@@ -157,3 +157,30 @@ func ExampleSliceDistinct() {
 	// [2 1 3]
 	// [1 2 3]
 }
+
+func TestSliceLen(t *testing.T) {
+	g := SliceLen(IntRange(1, 3), 1, 2)
+	rnd := newTestRand(1)
+	for _, size := range []int{0, 1, 10} {
+		for i := 0; i < 20; i++ {
+			v, ok := g.RValue(g.Random(rnd, size))
+			require.True(t, ok)
+			require.True(t, 1 <= len(v) && len(v) <= 2, "%v", v)
+		}
+	}
+	require.Equal(t, [][]int{{1}, {2}, {3}, {1, 1}, {2, 1}, {3, 1}, {1, 2}, {2, 2}, {3, 2}, {1, 3}, {2, 3}, {3, 3}},
+		geniterable.ToSlice(EnumerateValues(g, 3)))
+	for _, s := range iterable.ToSlice(g.Shrink([]int64{3, 3})) {
+		require.NotEmpty(t, s)
+	}
+}
+
+func TestSliceDistinctLen(t *testing.T) {
+	g := SliceDistinctLen(IntRange(0, 100), equality.Default[int](), 3, 3)
+	rnd := newTestRand(1)
+	for _, size := range []int{0, 1, 10} {
+		v, ok := g.RValue(g.Random(rnd, size))
+		require.True(t, ok)
+		require.Len(t, v, 3)
+	}
+}
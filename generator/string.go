@@ -9,19 +9,31 @@ import (
 	"github.com/peterzeller/go-stateful-test/generator/shrink"
 	"math/big"
 	"strings"
+	"unicode/utf8"
 )
 
 func String(chars ...rune) Generator[string, string] {
+	return StringLen(0, -1, chars...)
+}
+
+// StringLen is a generator for strings with a length (in runes) between minLen and maxLen.
+// A negative maxLen means that the length is not bounded.
+func StringLen(minLen, maxLen int, chars ...rune) Generator[string, string] {
 	if len(chars) == 0 {
 		chars = []rune{'a', 'b'}
 	}
+	checkLengthBounds(minLen, maxLen)
 	return genString{
-		chars: chars,
+		chars:  chars,
+		minLen: minLen,
+		maxLen: maxLen,
 	}
 }
 
 type genString struct {
-	chars []rune
+	chars  []rune
+	minLen int
+	maxLen int
 }
 
 func (g genString) Name() string {
@@ -29,18 +41,17 @@ func (g genString) Name() string {
 }
 
 func (g genString) Random(rnd Rand, size int) string {
-	r := rnd.R()
-	length := r.Intn(size + 1)
+	length := g.minLen + Choose(rnd, lengthSpan(g.minLen, g.maxLen, size+1))
 	var s strings.Builder
 	for i := 0; i < length; i++ {
-		s.WriteRune(g.chars[r.Intn(len(g.chars))])
+		s.WriteRune(g.chars[Choose(rnd, len(g.chars))])
 	}
 	return (s.String())
 }
 
 func (g genString) Enumerate(depth int) geniterable.Iterable[string] {
 	return geniterable.FlatMap(
-		geniterable.NonExhaustive(geniterable.Range(0, depth+1)),
+		enumerateLengths(g.minLen, g.maxLen, depth),
 		func(length int) geniterable.Iterable[string] {
 			return enumerateStrings(length, g.chars)
 		})
@@ -66,7 +77,10 @@ func enumerateStrings(length int, chars []rune) geniterable.Iterable[string] {
 
 func (g genString) Shrink(elem string) iterable.Iterable[string] {
 	runes := linked.FromIterable(iterable.FromString(elem))
-	return iterable.Map(shrink.ShrinkList(runes, g.shrinkRune),
+	shrinks := iterable.Filter(shrink.ShrinkList(runes, g.shrinkRune), func(runes *linked.List[rune]) bool {
+		return runes.Length() >= g.minLen
+	})
+	return iterable.Map(shrinks,
 		func(runes *linked.List[rune]) string {
 			var s strings.Builder
 			for it := iterable.Start[rune](runes); it.HasNext(); it.Next() {
@@ -105,5 +119,5 @@ func (g genString) Size(t string) *big.Int {
 
 func (g genString) RValue(elem string) (string, bool) {
 	// TODO check that chars match
-	return elem, true
+	return elem, inLengthBounds(utf8.RuneCountInString(elem), g.minLen, g.maxLen)
 }
@@ -22,3 +22,19 @@ func TestGenString_Enumerate(t *testing.T) {
 	s := String('a', 'b')
 	require.Equal(t, []string{"", "a", "b", "aa", "ab", "ba", "bb", "aaa", "aab", "aba", "abb", "baa", "bab", "bba", "bbb"}, geniterable.ToSlice(s.Enumerate(3)))
 }
+
+func TestStringLen(t *testing.T) {
+	s := StringLen(2, 3, 'a', 'b')
+	rnd := newTestRand(1)
+	for _, size := range []int{0, 1, 10} {
+		for i := 0; i < 20; i++ {
+			v, ok := s.RValue(s.Random(rnd, size))
+			require.True(t, ok)
+			require.True(t, 2 <= len(v) && len(v) <= 3, "%q", v)
+		}
+	}
+	require.Equal(t, []string{"bb", "ba", "ba", "aba", "baa"}, iterable.ToSlice(ShrinkValues(s, "bba")))
+	require.Equal(t, []string{"aa", "ab", "ba", "bb"}, geniterable.ToSlice(s.Enumerate(1)))
+	_, ok := s.RValue("a")
+	require.False(t, ok)
+}
@@ -31,6 +31,9 @@ func (g genUInt64) Name() string {
 }
 
 func (g genUInt64) Random(rnd Rand, size int) uint64 {
+	if c, ok := chooser(rnd); ok {
+		return g.min + c.Choose(g.max-g.min+1)
+	}
 	r := rnd.R()
 	p := r.Float64()
 	n := 1 + g.max - g.min
@@ -0,0 +1,121 @@
+// Package callsite determines the source location of log messages, similar to testing.T.
+// Frames in helper functions (marked with Helper) and in the non-test code of this module are skipped.
+package callsite
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// moduleDir is the root directory of this module
+var moduleDir = func() string {
+	_, file, _, ok := runtime.Caller(0)
+	if !ok {
+		return ""
+	}
+	// this file is in <moduleDir>/internal/callsite
+	return filepath.Dir(filepath.Dir(filepath.Dir(file)))
+}()
+
+// internalDirs are the packages of the module whose frames are not shown to users
+var internalDirs = []string{"quickcheck", "smallcheck", "pick", "statemachine", "generator", "statefulTest", "stats", "internal"}
+
+// isInternal returns true if the file belongs to the non-test code of this module.
+func isInternal(file string) bool {
+	if moduleDir == "" || strings.HasSuffix(file, "_test.go") {
+		return false
+	}
+	rel, err := filepath.Rel(moduleDir, file)
+	if err != nil {
+		return false
+	}
+	rel = filepath.ToSlash(rel)
+	for _, dir := range internalDirs {
+		if strings.HasPrefix(rel, dir+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// Helpers records the functions marked as test helpers.
+// The zero value is ready to use.
+type Helpers struct {
+	mu    sync.Mutex
+	names map[string]bool
+}
+
+// Mark marks the function that called the caller of Mark as a helper.
+// It is meant to be called from an implementation of Helper.
+func (h *Helpers) Mark() {
+	var pc [1]uintptr
+	if runtime.Callers(3, pc[:]) == 0 {
+		return
+	}
+	frame, _ := runtime.CallersFrames(pc[:]).Next()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.names == nil {
+		h.names = make(map[string]bool)
+	}
+	h.names[frame.Function] = true
+}
+
+func (h *Helpers) isHelper(function string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.names[function]
+}
+
+// Prefix returns the location of the first caller that is neither a helper, internal nor part of the runtime,
+// formatted as "file.go:line: ".
+// Skipping the runtime is needed when Prefix is called while recovering from a panic,
+// where the frame below the deferred function is runtime.gopanic.
+// Returns the empty string if there is no such caller.
+func (h *Helpers) Prefix() string {
+	var pcs [64]uintptr
+	// skip runtime.Callers and Prefix
+	n := runtime.Callers(2, pcs[:])
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if frame.File != "" && !isInternal(frame.File) && !h.isHelper(frame.Function) && !strings.HasPrefix(frame.Function, "runtime.") {
+			return fmt.Sprintf("%s:%d: ", filepath.Base(frame.File), frame.Line)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
+
+// TrimTrace removes the internal frames from the "Error Trace" of testify assertion messages.
+func TrimTrace(msg string) string {
+	if !strings.Contains(msg, "Error Trace:") {
+		return msg
+	}
+	lines := strings.Split(msg, "\n")
+	result := lines[:0]
+	for _, line := range lines {
+		if !strings.Contains(line, "Error Trace:") && isTraceFrame(strings.TrimSpace(line)) {
+			continue
+		}
+		result = append(result, line)
+	}
+	return strings.Join(result, "\n")
+}
+
+// isTraceFrame returns true if s is a trace line "file:line" that refers to internal code or the runtime.
+func isTraceFrame(s string) bool {
+	i := strings.LastIndex(s, ":")
+	if i < 0 || strings.ContainsAny(s, " \t") {
+		return false
+	}
+	file := s[:i]
+	if isInternal(file) {
+		return true
+	}
+	return strings.Contains(filepath.ToSlash(file), "/src/runtime/")
+}
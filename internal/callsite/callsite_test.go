@@ -0,0 +1,51 @@
+package callsite
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrimTrace(t *testing.T) {
+	run := filepath.Join(moduleDir, "quickcheck", "run.go")
+	user := filepath.Join(moduleDir, "examples", "example_test.go")
+	msg := "\n\tError Trace:\t" + user + ":58\n" +
+		"\t            \t\t\t\t" + run + ":37\n" +
+		"\t            \t\t\t\t/usr/local/go/src/runtime/asm_amd64.s:1264\n" +
+		"\t            \t\t\t\t" + user + ":52\n" +
+		"\tError:      \tShould be true\n"
+	require.Equal(t, "\n\tError Trace:\t"+user+":58\n"+
+		"\t            \t\t\t\t"+user+":52\n"+
+		"\tError:      \tShould be true\n", TrimTrace(msg))
+	require.Equal(t, run+":37", TrimTrace(run+":37"), "only assertion traces are trimmed")
+}
+
+func TestIsInternal(t *testing.T) {
+	require.True(t, isInternal(filepath.Join(moduleDir, "smallcheck", "state.go")))
+	require.False(t, isInternal(filepath.Join(moduleDir, "smallcheck", "state_test.go")))
+	require.False(t, isInternal(filepath.Join(moduleDir, "examples", "expect_error.go")))
+}
+
+func markHelper(h *Helpers) {
+	h.Mark()
+}
+
+// recordPanic recovers from a panic and records the prefix for a log message about it.
+func recordPanic(h *Helpers, prefix *string) {
+	markHelper(h)
+	if r := recover(); r != nil {
+		*prefix = h.Prefix()
+	}
+}
+
+func TestPanicPrefix(t *testing.T) {
+	var h Helpers
+	var prefix string
+	func() {
+		defer recordPanic(&h, &prefix)
+		panic("boom")
+	}()
+	// the prefix points to the panic and not to runtime/panic.go
+	require.Regexp(t, `^callsite_test\.go:\d+: $`, prefix)
+}
@@ -0,0 +1,191 @@
+// Package reproducer generates Go test functions that replay a failing test run with pick.Replay.
+// It is used by quickcheck and smallcheck.
+package reproducer
+
+import (
+	"fmt"
+	"go/format"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Step is a recorded step of a test run: either a picked value or the result of a call to HasMore.
+type Step struct {
+	Value     interface{}
+	IsHasMore bool
+	HasMore   bool
+}
+
+// Value records a picked value.
+func Value(v interface{}) Step {
+	return Step{Value: v}
+}
+
+// HasMore records the result of a call to HasMore.
+func HasMore(b bool) Step {
+	return Step{IsHasMore: true, HasMore: b}
+}
+
+// Generate returns the source code of a test function that replays the steps of a failing run of the given test.
+// The test name can be empty if it is not known.
+// runner is the function that was used to run the property, for example "quickcheck.Run".
+// If a picked value cannot be written as a Go literal, the test function uses the fallback statement instead.
+func Generate(testName string, runner string, steps []Step, fallback string) string {
+	name := "Test" + identifier(strings.TrimPrefix(testName, "Test")) + "Reproducer"
+	var b strings.Builder
+	if testName == "" {
+		_, _ = fmt.Fprintf(&b, "// %s replays a failing test run.\n", name)
+	} else {
+		_, _ = fmt.Fprintf(&b, "// %s replays the failing test run of %s.\n", name, testName)
+	}
+	_, _ = fmt.Fprintf(&b, "// Replace property with the function passed to %s.\n", runner)
+	_, _ = fmt.Fprintf(&b, "func %s(t *testing.T) {\n", name)
+	body, ok := replayCall(steps)
+	if ok {
+		b.WriteString(body)
+	} else {
+		b.WriteString("// the picked values cannot be written as Go literals, so the test replays the seed\n")
+		b.WriteString(fallback)
+		b.WriteString("\n")
+	}
+	b.WriteString("}\n")
+	src, err := format.Source([]byte(b.String()))
+	if err != nil {
+		// should not happen, but the unformatted code is still useful
+		return b.String()
+	}
+	return string(src)
+}
+
+// Write stores the reproducer for the given test in dir and returns the path of the file.
+// The file is stored under testdata, so it is not compiled by go test.
+func Write(dir string, testName string, src string) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, identifier(testName)+"_reproducer.go")
+	return path, os.WriteFile(path, []byte(src), 0o644)
+}
+
+func replayCall(steps []Step) (string, bool) {
+	var b strings.Builder
+	b.WriteString("pick.Replay(t, property,\n")
+	for _, s := range steps {
+		if s.IsHasMore {
+			_, _ = fmt.Fprintf(&b, "pick.More(%v),\n", s.HasMore)
+			continue
+		}
+		lit, ok := literal(reflect.ValueOf(s.Value), true)
+		if !ok {
+			return "", false
+		}
+		_, _ = fmt.Fprintf(&b, "pick.V(%s),\n", lit)
+	}
+	b.WriteString(")\n")
+	return b.String(), true
+}
+
+// literal writes v as a Go literal.
+// At the top level, basic values are written as constants of the predeclared type of their kind,
+// which pick.Replay converts to the type of the generator (for example to a named type).
+// The constant is only left untyped if its default type is the type of the kind,
+// so that values like uint64 constants above math.MaxInt64 compile.
+// Composite values must have unnamed types with elements of predeclared types, so that the literal compiles in any package.
+func literal(v reflect.Value, topLevel bool) (string, bool) {
+	if !v.IsValid() {
+		return "", false
+	}
+	t := v.Type()
+	if !topLevel && t.PkgPath() != "" {
+		return "", false
+	}
+	lit, ok := basicLiteral(v)
+	if ok {
+		if topLevel {
+			switch v.Kind() {
+			case reflect.Bool, reflect.Int, reflect.Float64, reflect.String:
+				// the default type of the constant
+			default:
+				lit = fmt.Sprintf("%s(%s)", v.Kind(), lit)
+			}
+		}
+		return lit, true
+	}
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		if t.Name() != "" {
+			return "", false
+		}
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return fmt.Sprintf("%s(nil)", t), true
+		}
+		elems := make([]string, v.Len())
+		for i := range elems {
+			e, ok := literal(v.Index(i), false)
+			if !ok {
+				return "", false
+			}
+			elems[i] = e
+		}
+		return fmt.Sprintf("%s{%s}", t, strings.Join(elems, ", ")), true
+	case reflect.Map:
+		if t.Name() != "" {
+			return "", false
+		}
+		if v.IsNil() {
+			return fmt.Sprintf("%s(nil)", t), true
+		}
+		var entries []string
+		iter := v.MapRange()
+		for iter.Next() {
+			k, ok1 := literal(iter.Key(), false)
+			e, ok2 := literal(iter.Value(), false)
+			if !ok1 || !ok2 {
+				return "", false
+			}
+			entries = append(entries, k+": "+e)
+		}
+		sort.Strings(entries)
+		return fmt.Sprintf("%s{%s}", t, strings.Join(entries, ", ")), true
+	default:
+		return "", false
+	}
+}
+
+// basicLiteral writes a value of a basic kind as an untyped constant
+func basicLiteral(v reflect.Value) (string, bool) {
+	switch v.Kind() {
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), true
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return "", false
+		}
+		return strconv.FormatFloat(f, 'g', -1, v.Type().Bits()), true
+	case reflect.String:
+		return strconv.Quote(v.String()), true
+	default:
+		return "", false
+	}
+}
+
+// identifier replaces all characters of s that cannot be used in a Go identifier
+func identifier(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+			return r
+		}
+		return '_'
+	}, s)
+}
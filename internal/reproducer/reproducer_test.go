@@ -0,0 +1,74 @@
+package reproducer
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type color int
+
+type point struct {
+	X, Y int
+}
+
+func TestLiteral(t *testing.T) {
+	tests := []struct {
+		value    interface{}
+		expected string
+		ok       bool
+	}{
+		{42, "42", true},
+		{int8(-3), "int8(-3)", true},
+		{uint64(7), "uint64(7)", true},
+		{uint64(math.MaxUint64), "uint64(18446744073709551615)", true},
+		{true, "true", true},
+		{"a\"b", `"a\"b"`, true},
+		{1.5, "1.5", true},
+		{float32(0.1), "float32(0.1)", true},
+		{color(2), "2", true},
+		{uint8(200), "uint8(200)", true},
+		{[]uint64{math.MaxUint64}, "[]uint64{18446744073709551615}", true},
+		{[]int{1, 2}, "[]int{1, 2}", true},
+		{[]string(nil), "[]string(nil)", true},
+		{[2]bool{true, false}, "[2]bool{true, false}", true},
+		{map[string]int{"b": 2, "a": 1}, `map[string]int{"a": 1, "b": 2}`, true},
+		{[][]int{{1}, {}}, "[][]int{[]int{1}, []int{}}", true},
+		{math.NaN(), "", false},
+		{[]color{1}, "", false},
+		{point{1, 2}, "", false},
+		{&point{}, "", false},
+		{nil, "", false},
+	}
+	for _, test := range tests {
+		lit, ok := literal(reflect.ValueOf(test.value), true)
+		require.Equal(t, test.ok, ok, "%#v", test.value)
+		require.Equal(t, test.expected, lit, "%#v", test.value)
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	src := Generate("TestQueue/small", "quickcheck.Run", []Step{Value(1), HasMore(true), Value("put"), HasMore(false)}, "fallback()")
+	require.Equal(t, `// TestQueue_smallReproducer replays the failing test run of TestQueue/small.
+// Replace property with the function passed to quickcheck.Run.
+func TestQueue_smallReproducer(t *testing.T) {
+	pick.Replay(t, property,
+		pick.V(1),
+		pick.More(true),
+		pick.V("put"),
+		pick.More(false),
+	)
+}
+`, src)
+
+	src = Generate("", "quickcheck.Run", []Step{Value(&point{})}, "quickcheck.Run(t, quickcheck.Config{Seed: 1}, property)")
+	require.Equal(t, `// TestReproducer replays a failing test run.
+// Replace property with the function passed to quickcheck.Run.
+func TestReproducer(t *testing.T) {
+	// the picked values cannot be written as Go literals, so the test replays the seed
+	quickcheck.Run(t, quickcheck.Config{Seed: 1}, property)
+}
+`, src)
+}
@@ -1,6 +1,8 @@
 package pick
 
 import (
+	"sort"
+
 	"github.com/peterzeller/go-fun/dict/hashdict"
 	"github.com/peterzeller/go-fun/hash"
 	"github.com/peterzeller/go-fun/reducer"
@@ -28,3 +30,86 @@ func Switch(t statefulTest.T, of Cases) {
 	// execute function
 	of[key]()
 }
+
+// WeightedCase is a case for SwitchWeighted.
+type WeightedCase struct {
+	// Weight of the case, cases are chosen with a probability proportional to their weight
+	Weight int
+	// Enabled checks whether the case can be chosen (optional, default: always enabled)
+	Enabled func() bool
+	// Run is executed when the case is chosen
+	Run func()
+}
+
+type WeightedCases map[string]WeightedCase
+
+// SwitchWeighted executes one function from the map.
+// Random runs choose the cases with a probability proportional to their weight.
+// Enumeration ignores the weights, but starts with the heaviest cases, which are also preferred when shrinking.
+// Cases that are not enabled are never chosen, and the test run is discarded if no case is enabled.
+func SwitchWeighted(t statefulTest.T, of WeightedCases) {
+	keys, ok := enabledKeys(t, of)
+	if !ok {
+		return
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		wi, wj := of[keys[i]].Weight, of[keys[j]].Weight
+		if wi != wj {
+			return wi > wj
+		}
+		return keys[i] < keys[j]
+	})
+	gens := make([]generator.WeightedGen[string, string], len(keys))
+	for i, k := range keys {
+		gens[i] = generator.Weighted(of[k].Weight, generator.Constant(k))
+	}
+	key := Val(t, generator.Frequency(gens...))
+	of[key].Run()
+}
+
+// GuardedCase is a case for SwitchGuarded.
+type GuardedCase struct {
+	// Enabled checks whether the case can be chosen (optional, default: always enabled)
+	Enabled func() bool
+	// Run is executed when the case is chosen
+	Run func()
+}
+
+type GuardedCases map[string]GuardedCase
+
+// SwitchGuarded executes one function from the map, like Switch.
+// Only the cases that are enabled are offered to the generator,
+// and the test run is discarded if no case is enabled.
+func SwitchGuarded(t statefulTest.T, of GuardedCases) {
+	keys, ok := enabledKeys(t, of)
+	if !ok {
+		return
+	}
+	sort.Strings(keys)
+	key := Val(t, generator.OneConstantOf(keys...))
+	of[key].Run()
+}
+
+func (c GuardedCase) enabled() bool {
+	return c.Enabled == nil || c.Enabled()
+}
+
+func (c WeightedCase) enabled() bool {
+	return c.Enabled == nil || c.Enabled()
+}
+
+// enabledKeys returns the keys of the enabled cases in an unspecified order.
+// If no case is enabled, the test run is discarded and false is returned.
+func enabledKeys[C interface{ enabled() bool }](t statefulTest.T, of map[string]C) ([]string, bool) {
+	keys := make([]string, 0, len(of))
+	for k, c := range of {
+		if c.enabled() {
+			keys = append(keys, k)
+		}
+	}
+	if len(keys) == 0 {
+		t.Discard()
+		return nil, false
+	}
+	return keys, true
+}
@@ -0,0 +1,155 @@
+package pick
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/peterzeller/go-stateful-test/generator"
+	"github.com/peterzeller/go-stateful-test/statefulTest"
+)
+
+// Step is a recorded step of a test run for Replay.
+type Step struct {
+	value     interface{}
+	isHasMore bool
+	hasMore   bool
+}
+
+// V is a step where the test picks the value v.
+// If v has a different type than the values of the generator (for example an untyped constant), it is converted.
+func V(v interface{}) Step {
+	return Step{value: v}
+}
+
+// More is a step where HasMore returns b.
+func More(b bool) Step {
+	return Step{isHasMore: true, hasMore: b}
+}
+
+// ReplayT is the subset of testing.T used by Replay.
+type ReplayT interface {
+	Errorf(format string, args ...interface{})
+	FailNow()
+	Logf(format string, args ...interface{})
+}
+
+// Replay runs the test function f once, where PickValue and HasMore return the given steps in order.
+// quickcheck and smallcheck can generate a call to Replay for a failing test run (Config.Reproducer),
+// which can be used as a regression test.
+// The test fails if f does not use exactly the given steps.
+func Replay(t ReplayT, f func(t statefulTest.T), steps ...Step) {
+	r := &replayT{t: t, steps: steps}
+	defer r.runCleanups()
+	func() {
+		defer func() {
+			if rec := recover(); rec != nil {
+				if err, ok := rec.(error); ok && errors.Is(err, errReplayDiscarded) {
+					t.Logf("pick.Replay: test run was discarded")
+					return
+				}
+				panic(rec)
+			}
+		}()
+		f(r)
+	}()
+	if r.pos < len(r.steps) {
+		t.Errorf("pick.Replay: test run ended after %d of %d steps", r.pos, len(r.steps))
+	}
+}
+
+var errReplayDiscarded = fmt.Errorf("replayed test run discarded")
+
+type replayT struct {
+	t       ReplayT
+	steps   []Step
+	pos     int
+	cleanup []func()
+}
+
+var _ statefulTest.T = &replayT{}
+
+func (r *replayT) next(isHasMore bool, what string) Step {
+	if r.pos >= len(r.steps) {
+		r.t.Errorf("pick.Replay: test run uses more than the %d recorded steps (%s)", len(r.steps), what)
+		r.t.FailNow()
+	}
+	s := r.steps[r.pos]
+	if s.isHasMore != isHasMore {
+		r.t.Errorf("pick.Replay: step %d does not match the test run (%s)", r.pos, what)
+		r.t.FailNow()
+	}
+	r.pos++
+	return s
+}
+
+func (r *replayT) PickValue(gen generator.UntypedGenerator) generator.UV {
+	s := r.next(false, "PickValue "+gen.Name())
+	return generator.UV{Value: convertTo(gen, s.value)}
+}
+
+// convertTo converts v to the type of the values of gen, if necessary.
+func convertTo(gen generator.UntypedGenerator, v interface{}) interface{} {
+	target := generator.ValueType(gen)
+	value := reflect.ValueOf(v)
+	if target == nil || !value.IsValid() || value.Type() == target || !value.Type().ConvertibleTo(target) {
+		return v
+	}
+	return value.Convert(target).Interface()
+}
+
+func (r *replayT) HasMore() bool {
+	return r.next(true, "HasMore").hasMore
+}
+
+func (r *replayT) Errorf(format string, args ...interface{}) {
+	if h, ok := r.t.(interface{ Helper() }); ok {
+		h.Helper()
+	}
+	r.t.Errorf(format, args...)
+}
+
+func (r *replayT) FailNow() {
+	r.t.FailNow()
+}
+
+func (r *replayT) Logf(format string, args ...any) {
+	if h, ok := r.t.(interface{ Helper() }); ok {
+		h.Helper()
+	}
+	r.t.Logf(format, args...)
+}
+
+// Helper has no effect, because the helper frames cannot be forwarded to the underlying testing.T.
+// Errorf and Logf are marked as helpers instead, so that the testing.T reports their callers.
+func (r *replayT) Helper() {}
+
+func (r *replayT) Cleanup(f func()) {
+	r.cleanup = append(r.cleanup, f)
+}
+
+func (r *replayT) runCleanups() {
+	for _, f := range r.cleanup {
+		f()
+	}
+}
+
+func (r *replayT) Assume(cond bool) {
+	if !cond {
+		r.Discard()
+	}
+}
+
+func (r *replayT) Discard() {
+	panic(errReplayDiscarded)
+}
+
+// Labels have no effect when replaying a single run.
+
+func (r *replayT) Label(labels ...string) {}
+
+func (r *replayT) Classify(cond bool, label string) {}
+
+func (r *replayT) Collect(value interface{}) {}
+
+func (r *replayT) Cover(minPercent float64, cond bool, label string) {}
@@ -0,0 +1,280 @@
+package quickcheck
+
+import (
+	"context"
+	"math/bits"
+	"math/rand"
+	"strings"
+
+	"github.com/peterzeller/go-stateful-test/generator"
+	"github.com/peterzeller/go-stateful-test/quickcheck/tree"
+)
+
+// Engine selects how quickcheck generates and shrinks test runs.
+type Engine int
+
+const (
+	// GenTreeEngine records the generated values and shrinks them using the Shrink functions of the generators.
+	// This is the default.
+	GenTreeEngine Engine = iota
+	// ChoiceSequenceEngine records the raw random numbers drawn by the generators as a choice sequence
+	// and shrinks the test run by minimizing this sequence (deleting spans, lowering values and sorting).
+	// Every generator shrinks this way, including generators without a Shrink function and generators
+	// that depend on previously generated values, because shrunk values are always produced by running the generators.
+	ChoiceSequenceEngine
+)
+
+// choiceSource is a rand.Source that records all values it returns.
+// The first values are taken from prefix.
+// After the prefix, values are taken from random, or are zero if random is nil.
+type choiceSource struct {
+	prefix  []uint64
+	random  rand.Source64
+	choices []uint64
+	// mismatch is set when a value of the prefix was out of the range of its choice
+	mismatch bool
+}
+
+var _ rand.Source64 = &choiceSource{}
+
+func (c *choiceSource) Uint64() uint64 {
+	v := c.next()
+	c.choices = append(c.choices, v)
+	return v
+}
+
+// choose implements generator.Chooser.
+// It records the returned number instead of the raw value, so that lowering a choice lowers the generated number.
+// A choice with only one possible value is not recorded.
+func (c *choiceSource) choose(n uint64) uint64 {
+	if n == 1 {
+		return 0
+	}
+	var v uint64
+	if i := len(c.choices); i < len(c.prefix) {
+		v = c.prefix[i]
+		if n != 0 && v >= n {
+			c.mismatch = true
+		}
+	} else if c.random != nil {
+		v = c.randomChoice(n)
+	}
+	if n != 0 {
+		v %= n
+	}
+	c.choices = append(c.choices, v)
+	return v
+}
+
+// randomChoice draws a new choice below n (or any uint64 if n is 0).
+// For large n, the bit length of the choice is uniformly distributed, so that small numbers are drawn often.
+func (c *choiceSource) randomChoice(n uint64) uint64 {
+	if n != 0 && n <= 1024 {
+		return c.random.Uint64() % n
+	}
+	bitLen := c.random.Uint64() % uint64(bits.Len64(n-1)+1)
+	return c.random.Uint64() & (1<<bitLen - 1)
+}
+
+// next returns the next value from the prefix or from random
+func (c *choiceSource) next() uint64 {
+	if i := len(c.choices); i < len(c.prefix) {
+		return c.prefix[i]
+	} else if c.random != nil {
+		return c.random.Uint64()
+	}
+	return 0
+}
+
+func (c *choiceSource) Int63() int64 {
+	return int64(c.Uint64() >> 1)
+}
+
+func (c *choiceSource) Seed(seed int64) {
+	c.choices = nil
+}
+
+// recorded returns the recorded choices without trailing zeros.
+// Trailing zeros can be removed, because a replayed sequence continues with zeros.
+func (c *choiceSource) recorded() []uint64 {
+	n := len(c.choices)
+	for n > 0 && c.choices[n-1] == 0 {
+		n--
+	}
+	return c.choices[:n]
+}
+
+// newState creates the state for a test run with the given seed and size using the configured engine.
+func newState(cfg Config, seed int64, size int) *state {
+	if cfg.Engine == ChoiceSequenceEngine {
+		return initChoiceState(cfg, seed, size, nil, rand.NewSource(seed).(rand.Source64))
+	}
+	return initState(cfg, seed, size)
+}
+
+// initChoiceState creates a state that records its random choices.
+// The run replays the given prefix and continues with values from random (or zeros if random is nil).
+func initChoiceState(cfg Config, seed int64, size int, prefix []uint64, random rand.Source64) *state {
+	src := &choiceSource{prefix: prefix, random: random}
+	s := &state{
+		mainFork: &fork{
+			genTree: tree.NewGenNodeFromSource(seed, src),
+			maxSize: size,
+		},
+		log: strings.Builder{},
+		cfg: cfg,
+		source: func(seed int64) rand.Source {
+			return src
+		},
+		choices: src,
+		size:    size,
+	}
+	s.mainFork.parent = s
+	return s
+}
+
+// choiceRand is the generator.Rand for generators in the choice sequence engine.
+// Besides the underlying rand.Rand, it offers generator.Chooser to draw numbers directly from the choice sequence.
+type choiceRand struct {
+	f *fork
+}
+
+var _ generator.Chooser = choiceRand{}
+
+func (r choiceRand) Fork(name string) generator.Rand {
+	// all forks draw from the same choice sequence
+	return r
+}
+
+func (r choiceRand) HasMore() bool {
+	return r.f.HasMore()
+}
+
+func (r choiceRand) R() *rand.Rand {
+	return r.f.R()
+}
+
+func (r choiceRand) Choose(n uint64) uint64 {
+	return r.f.parent.choices.choose(n)
+}
+
+// pickChoice generates a value directly from the choice sequence without recording it for shrinking.
+func (f *fork) pickChoice(gen generator.UntypedGenerator) generator.UV {
+	v, ok := gen.RValue(gen.Random(choiceRand{f}, f.maxSize))
+	if !ok {
+		// the generator could not produce a valid value from these choices
+		f.parent.Discard()
+	}
+	return v
+}
+
+// shrinkChoices shrinks the failing state s by minimizing its choice sequence.
+// Candidates are only accepted when they fail and are smaller in shortlex order,
+// so shrinking always terminates.
+func shrinkChoices(ctx context.Context, s *state, runState func(*state) (result *state)) *state {
+	sh := &choiceShrinker{ctx: ctx, best: s, runState: runState}
+	for improved := true; improved && ctx.Err() == nil; {
+		before := sh.best
+		sh.deleteSpans()
+		sh.zeroSpans()
+		sh.lowerValues()
+		sh.sortPairs()
+		improved = sh.best != before
+	}
+	return sh.best
+}
+
+type choiceShrinker struct {
+	ctx      context.Context
+	best     *state
+	runState func(*state) *state
+}
+
+// current returns the choices of the best run.
+// Trailing zeros are kept, so that moving a value to the end of the sequence does not make it smaller.
+func (sh *choiceShrinker) current() []uint64 {
+	return sh.best.choices.choices
+}
+
+// try runs the test with the candidate choices and keeps the run if it fails and is smaller than the best run so far.
+func (sh *choiceShrinker) try(candidate []uint64) bool {
+	if sh.ctx.Err() != nil || !shortlexLess(candidate, sh.current()) {
+		return false
+	}
+	s := initChoiceState(sh.best.cfg, sh.best.mainFork.genTree.Seed, sh.best.size, candidate, nil)
+	res := sh.runState(s)
+	s.runCleanups()
+	if res == nil || !res.failed || !shortlexLess(res.choices.choices, sh.current()) {
+		return false
+	}
+	sh.best = res
+	return true
+}
+
+// deleteSpans tries to remove spans of choices, starting with long spans.
+func (sh *choiceShrinker) deleteSpans() {
+	for k := 8; k >= 1; k /= 2 {
+		for i := 0; i+k <= len(sh.current()) && sh.ctx.Err() == nil; {
+			cur := sh.current()
+			candidate := append(append([]uint64{}, cur[:i]...), cur[i+k:]...)
+			if !sh.try(candidate) {
+				i++
+			}
+		}
+	}
+}
+
+// zeroSpans tries to replace spans of choices with zeros.
+func (sh *choiceShrinker) zeroSpans() {
+	for k := 8; k >= 2; k /= 2 {
+		for i := 0; i+k <= len(sh.current()) && sh.ctx.Err() == nil; i++ {
+			candidate := append([]uint64{}, sh.current()...)
+			for j := i; j < i+k; j++ {
+				candidate[j] = 0
+			}
+			sh.try(candidate)
+		}
+	}
+}
+
+// lowerValues tries to lower each choice, using a binary search for the smallest value that still fails.
+func (sh *choiceShrinker) lowerValues() {
+	for i := 0; i < len(sh.current()) && sh.ctx.Err() == nil; i++ {
+		lo, hi := uint64(0), sh.current()[i]
+		for lo < hi && i < len(sh.current()) && sh.ctx.Err() == nil {
+			mid := lo + (hi-lo)/2
+			candidate := append([]uint64{}, sh.current()...)
+			candidate[i] = mid
+			if sh.try(candidate) {
+				hi = mid
+			} else {
+				lo = mid + 1
+			}
+		}
+	}
+}
+
+// sortPairs tries to swap adjacent choices that are not in ascending order.
+func (sh *choiceShrinker) sortPairs() {
+	for i := 0; i+1 < len(sh.current()) && sh.ctx.Err() == nil; i++ {
+		cur := sh.current()
+		if cur[i] > cur[i+1] {
+			candidate := append([]uint64{}, cur...)
+			candidate[i], candidate[i+1] = candidate[i+1], candidate[i]
+			sh.try(candidate)
+		}
+	}
+}
+
+// shortlexLess checks whether a is shorter than b, or has the same length and is lexicographically smaller.
+func shortlexLess(a, b []uint64) bool {
+	if len(a) != len(b) {
+		return len(a) < len(b)
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return false
+}
@@ -0,0 +1,23 @@
+package quickcheck
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestChoiceSource(t *testing.T) {
+	src := &choiceSource{prefix: []uint64{5, 0, 7}}
+	for i := 0; i < 5; i++ {
+		src.Uint64()
+	}
+	require.Equal(t, []uint64{5, 0, 7, 0, 0}, src.choices)
+	require.Equal(t, []uint64{5, 0, 7}, src.recorded())
+}
+
+func TestShortlexLess(t *testing.T) {
+	require.True(t, shortlexLess([]uint64{9}, []uint64{1, 1}))
+	require.True(t, shortlexLess([]uint64{1, 2}, []uint64{1, 3}))
+	require.False(t, shortlexLess([]uint64{1, 3}, []uint64{1, 3}))
+	require.False(t, shortlexLess([]uint64{1, 1}, []uint64{9}))
+}
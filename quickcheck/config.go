@@ -1,14 +1,52 @@
 package quickcheck
 
 import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
 	"testing"
 	"time"
 )
 
 type Config struct {
+	// NumberOfRuns is the number of successful (not discarded) runs (default 100)
 	NumberOfRuns      int
 	MaxShrinkDuration time.Duration
 	PrintAllLogs      bool
+	// MaxDiscardRatio is the maximum number of discarded runs per successful run (default 10).
+	// The test fails if more runs are discarded, because then the property is not tested sufficiently.
+	MaxDiscardRatio int
+	// Seed is the base seed for the random number generator.
+	// Run number i uses the seed Seed+i, so a failure can be reproduced by using the same base seed again.
+	// If Seed is 0, the seed is taken from the -quickcheck.seed flag or the QUICKCHECK_SEED environment variable.
+	// If neither is set, a random seed is chosen for each invocation.
+	// The value 0 always means "not set", also for the flag and the environment variable,
+	// so 0 itself cannot be used as a base seed. Random seeds are never 0, so every reported seed can be replayed.
+	Seed int64
+	// FailureDBDir is the directory in which shrunk counterexamples are stored (default: testdata/quickcheck).
+	// A relative path is resolved against the working directory, which is the package directory under go test,
+	// so by default every failing test with a name writes a file into the source tree of the package.
+	// Stored counterexamples are replayed before the random runs in later invocations of the test.
+	FailureDBDir string
+	// DisableFailureDB disables storing and replaying counterexamples, so that failing tests do not write counterexample files.
+	DisableFailureDB bool
+	// Engine selects how test runs are generated and shrunk (default: GenTreeEngine).
+	Engine Engine
+	// MinSize and MaxSize are the bounds for the size passed to the generators (default: 1 and 100).
+	// Sizes below 1 are raised to 1.
+	// The size also controls how likely HasMore returns true.
+	MinSize int
+	MaxSize int
+	// SizeSchedule computes the size for each run (default: LinearSizes).
+	SizeSchedule SizeSchedule
+	// Parallelism is the number of goroutines that execute test runs concurrently (default: 1).
+	// Each run has its own state and cleanups, so the test function must only share thread-safe resources between runs.
+	// The reported failure is the same as with a sequential execution, and shrinking is always sequential.
+	Parallelism int
+	// Reproducer enables printing a Go test function that replays the shrunk failure with pick.Replay.
+	// If the test has a name, the test function is also stored in FailureDBDir.
+	Reproducer bool
 }
 
 type TestingT interface {
@@ -19,3 +57,31 @@ type TestingT interface {
 }
 
 var _ TestingT = &testing.T{}
+
+// seedEnvVar is the environment variable that can be used to set the base seed.
+const seedEnvVar = "QUICKCHECK_SEED"
+
+var seedFlag = flag.Int64("quickcheck.seed", 0, "base seed for quickcheck runs (0 = random seed)")
+
+// resolveSeed determines the base seed to use for a call to Run.
+func resolveSeed(cfg Config) (int64, error) {
+	if cfg.Seed != 0 {
+		return cfg.Seed, nil
+	}
+	if *seedFlag != 0 {
+		return *seedFlag, nil
+	}
+	if env := os.Getenv(seedEnvVar); env != "" {
+		seed, err := strconv.ParseInt(env, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid value for %s: %w", seedEnvVar, err)
+		}
+		if seed != 0 {
+			return seed, nil
+		}
+	}
+	if seed := time.Now().UnixNano(); seed != 0 {
+		return seed, nil
+	}
+	return 1, nil
+}
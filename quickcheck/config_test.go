@@ -0,0 +1,41 @@
+package quickcheck
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveSeed(t *testing.T) {
+	t.Setenv(seedEnvVar, "")
+	seed, err := resolveSeed(Config{Seed: 42})
+	require.NoError(t, err)
+	require.Equal(t, int64(42), seed)
+
+	t.Setenv(seedEnvVar, "7")
+	seed, err = resolveSeed(Config{})
+	require.NoError(t, err)
+	require.Equal(t, int64(7), seed)
+
+	// 0 means "not set", so a random seed is chosen
+	t.Setenv(seedEnvVar, "0")
+	seed, err = resolveSeed(Config{})
+	require.NoError(t, err)
+	require.NotZero(t, seed)
+
+	// the flag takes priority over the environment variable
+	t.Setenv(seedEnvVar, "7")
+	oldFlag := *seedFlag
+	t.Cleanup(func() {
+		*seedFlag = oldFlag
+	})
+	*seedFlag = 5
+	seed, err = resolveSeed(Config{})
+	require.NoError(t, err)
+	require.Equal(t, int64(5), seed)
+	*seedFlag = 0
+
+	t.Setenv(seedEnvVar, "x")
+	_, err = resolveSeed(Config{})
+	require.Error(t, err)
+}
@@ -0,0 +1,245 @@
+package quickcheck
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultFailureDBDir is the directory used for storing counterexamples when Config.FailureDBDir is not set.
+var DefaultFailureDBDir = filepath.Join("testdata", "quickcheck")
+
+// FailureDB stores counterexamples found by quickcheck, so that they can be replayed in later test runs.
+// Each test has its own file in the directory Dir, named after the test.
+type FailureDB struct {
+	Dir string
+}
+
+// Counterexample is a failing test run stored in a FailureDB.
+// A counterexample is replayed from the random choices it records, so it does not depend on how runs are derived from seeds.
+//
+// With the ChoiceSequenceEngine, the choices are the shrunk choice sequence, which reproduces the shrunk run directly.
+// The GenTreeEngine shrinks with the Shrink functions of the generators, which do not draw random values.
+// Its counterexamples therefore record the random values of the failing run before shrinking,
+// and the run is shrunk again when it is replayed.
+type Counterexample struct {
+	// Seed of the failing run
+	Seed int64 `json:"seed"`
+	// Size of the failing run
+	Size int `json:"size"`
+	// Engine that found the counterexample
+	Engine Engine `json:"engine,omitempty"`
+	// Choices are the random choices of the counterexample
+	Choices []uint64 `json:"choices,omitempty"`
+	// Added is the time when the counterexample was found
+	Added time.Time `json:"added"`
+}
+
+type failureDBFile struct {
+	Counterexamples []Counterexample `json:"counterexamples"`
+}
+
+// Path returns the file that stores the counterexamples for the given test.
+func (db FailureDB) Path(testName string) string {
+	var name strings.Builder
+	for _, r := range testName {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '.' {
+			name.WriteRune(r)
+		} else {
+			name.WriteRune('_')
+		}
+	}
+	name.WriteString(".json")
+	return filepath.Join(db.Dir, name.String())
+}
+
+// Load the counterexamples stored for the given test.
+// Returns an empty slice if there are no counterexamples for the test.
+func (db FailureDB) Load(testName string) ([]Counterexample, error) {
+	data, err := os.ReadFile(db.Path(testName))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var f failureDBFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("could not parse %s: %w", db.Path(testName), err)
+	}
+	return f.Counterexamples, nil
+}
+
+// Save replaces the counterexamples stored for the given test.
+// If the slice is empty, the file for the test is removed.
+func (db FailureDB) Save(testName string, counterexamples []Counterexample) error {
+	path := db.Path(testName)
+	if len(counterexamples) == 0 {
+		err := os.Remove(path)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+	data, err := json.MarshalIndent(failureDBFile{Counterexamples: counterexamples}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(db.Dir, 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// Add a counterexample for the given test.
+// Counterexamples that are already stored are not added again.
+func (db FailureDB) Add(testName string, c Counterexample) error {
+	counterexamples, err := db.Load(testName)
+	if err != nil {
+		return err
+	}
+	for _, existing := range counterexamples {
+		if existing.sameRun(c) {
+			return nil
+		}
+	}
+	return db.Save(testName, append(counterexamples, c))
+}
+
+// Prune removes all counterexamples of the given test for which keep returns false.
+func (db FailureDB) Prune(testName string, keep func(c Counterexample) bool) error {
+	counterexamples, err := db.Load(testName)
+	if err != nil {
+		return err
+	}
+	var kept []Counterexample
+	for _, c := range counterexamples {
+		if keep(c) {
+			kept = append(kept, c)
+		}
+	}
+	return db.Save(testName, kept)
+}
+
+// PruneOlderThan removes all counterexamples of the given test that were added before the given time.
+func (db FailureDB) PruneOlderThan(testName string, t time.Time) error {
+	return db.Prune(testName, func(c Counterexample) bool {
+		return !c.Added.Before(t)
+	})
+}
+
+// Clear removes all counterexamples of the given test.
+func (db FailureDB) Clear(testName string) error {
+	return db.Save(testName, nil)
+}
+
+func (c Counterexample) sameRun(other Counterexample) bool {
+	if c.Seed != other.Seed || c.Size != other.Size || c.Engine != other.Engine || len(c.Choices) != len(other.Choices) {
+		return false
+	}
+	for i := range c.Choices {
+		if c.Choices[i] != other.Choices[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// failureDB returns the FailureDB and the test name to use for the given test.
+// The database is only used if it is not disabled and the test has a name.
+func failureDB(t TestingT, cfg Config) (FailureDB, string, bool) {
+	if cfg.DisableFailureDB {
+		return FailureDB{}, "", false
+	}
+	named, ok := t.(interface{ Name() string })
+	if !ok {
+		return FailureDB{}, "", false
+	}
+	return FailureDB{Dir: cfg.FailureDBDir}, named.Name(), true
+}
+
+// newCounterexample returns the counterexample to store for the failing run s, which was shrunk to shrunk.
+func newCounterexample(s, shrunk *state) Counterexample {
+	if shrunk.choices != nil {
+		return Counterexample{
+			Seed:    shrunk.mainFork.genTree.Seed,
+			Size:    shrunk.size,
+			Engine:  ChoiceSequenceEngine,
+			Choices: shrunk.choices.recorded(),
+			Added:   time.Now(),
+		}
+	}
+	return Counterexample{
+		Seed:    s.mainFork.genTree.Seed,
+		Size:    s.size,
+		Choices: s.recording.values,
+		Added:   time.Now(),
+	}
+}
+
+// replayCounterexample runs the stored counterexample c.
+// Returns the state of the run and whether the counterexample is stale,
+// i.e. the run does not match the recorded choices anymore, because the test or its generators have changed.
+func replayCounterexample(cfg Config, c Counterexample, runState func(*state) *state) (*state, bool) {
+	if c.Engine == ChoiceSequenceEngine {
+		s := initChoiceState(cfg, c.Seed, c.Size, c.Choices, nil)
+		runState(s)
+		s.runCleanups()
+		return s, s.choices.mismatch
+	}
+	s := initStateFromSource(cfg, c.Seed, c.Size, func(seed int64) rand.Source {
+		return &recordingSource{prefix: c.Choices, random: rand.NewSource(seed).(rand.Source64)}
+	})
+	runState(s)
+	s.runCleanups()
+	return s, s.recording.extended
+}
+
+// recordingSource is the rand.Source of the GenTreeEngine.
+// It records the values it returns, so that a failing run can be stored in the FailureDB.
+// The first values are taken from prefix and the remaining values from random.
+type recordingSource struct {
+	prefix []uint64
+	random rand.Source64
+	values []uint64
+	// extended is set when more values were drawn than the prefix contains
+	extended bool
+}
+
+var _ rand.Source64 = &recordingSource{}
+
+func (r *recordingSource) Int63() int64 {
+	var v int64
+	if i := len(r.values); i < len(r.prefix) {
+		v = int64(r.prefix[i] & math.MaxInt64)
+	} else {
+		r.extended = true
+		v = r.random.Int63()
+	}
+	r.values = append(r.values, uint64(v))
+	return v
+}
+
+func (r *recordingSource) Uint64() uint64 {
+	var v uint64
+	if i := len(r.values); i < len(r.prefix) {
+		v = r.prefix[i]
+	} else {
+		r.extended = true
+		v = r.random.Uint64()
+	}
+	r.values = append(r.values, v)
+	return v
+}
+
+func (r *recordingSource) Seed(seed int64) {
+	r.values = nil
+	r.random.Seed(seed)
+}
@@ -0,0 +1,132 @@
+package quickcheck
+
+import (
+	"encoding/binary"
+	"math/bits"
+	"math/rand"
+	"testing"
+
+	"github.com/peterzeller/go-stateful-test/generator"
+	"github.com/peterzeller/go-stateful-test/statefulTest"
+)
+
+// Fuzz runs the test function `f` as a native Go fuzz test with the default configuration.
+// Instead of a random number generator, the values picked by the generators are taken from the input of the fuzzer,
+// so `go test -fuzz` can use coverage guidance to find failing test runs.
+// This includes the values that generators draw from a Rand returned by Fork.
+//
+// When a failing input is found, the test run is shrunk using the Shrink functions of the generators,
+// like in Run, and only the shrunk test run is shown.
+// Byte-level minimization by the fuzzer is not needed, so it can be disabled with -fuzzminimizetime=0.
+//
+// Without the -fuzz flag, `go test` only runs the seed corpus and the inputs stored in testdata/fuzz.
+func Fuzz(fz *testing.F, f func(t statefulTest.T)) {
+	FuzzWithConfig(fz, Config{}, f)
+}
+
+// FuzzWithConfig is like Fuzz, but uses the given configuration.
+// The seed and the number of runs are ignored, because the fuzzer provides the inputs.
+func FuzzWithConfig(fz *testing.F, cfg Config, f func(t statefulTest.T)) {
+	cfg = setDefaults(cfg)
+	fz.Add([]byte{})
+	fz.Fuzz(func(t *testing.T, data []byte) {
+		runFuzzInput(t, cfg, data, f)
+	})
+}
+
+// runFuzzInput executes f with values taken from data and reports a shrunk failure to t.
+func runFuzzInput(t TestingT, cfg Config, data []byte, f func(t statefulTest.T)) {
+	runState := stateRunner(t, cfg, f)
+	s := initStateFromSource(cfg, 0, cfg.MaxSize, func(seed int64) rand.Source {
+		return newByteSource(data)
+	})
+	failed := runState(s)
+	s.runCleanups()
+	if failed == nil {
+		return
+	}
+	t.Logf("Found error for fuzz input, shrinking testcase ...")
+	shrunkS := shrinkFailure(t, cfg, s, runState)
+	if shrunkS.failed {
+		t.FailNow()
+	}
+}
+
+// byteSource is a rand.Source that takes its values from a byte slice, as provided by the Go fuzzer.
+// Generators usually draw their numbers with choose (see fuzzRand), which consumes only the bytes needed for the number.
+// Other random values consume 8 bytes in big-endian order, so that the first bytes have the largest influence.
+// Once all bytes are consumed, the source returns zeros, which makes generators pick their simplest values.
+type byteSource struct {
+	data []byte
+	pos  int
+}
+
+var _ rand.Source64 = &byteSource{}
+
+func newByteSource(data []byte) *byteSource {
+	return &byteSource{data: data}
+}
+
+func (b *byteSource) Uint64() uint64 {
+	var buf [8]byte
+	if b.pos < len(b.data) {
+		b.pos += copy(buf[:], b.data[b.pos:])
+	}
+	return binary.BigEndian.Uint64(buf[:])
+}
+
+// choose returns a number between 0 and n-1 (or any uint64 if n is 0),
+// read from the smallest number of bytes that can represent n-1.
+func (b *byteSource) choose(n uint64) uint64 {
+	if n == 1 {
+		return 0
+	}
+	numBytes := 8
+	if n != 0 {
+		numBytes = (bits.Len64(n-1) + 7) / 8
+	}
+	var v uint64
+	for i := 0; i < numBytes; i++ {
+		v <<= 8
+		if b.pos < len(b.data) {
+			v |= uint64(b.data[b.pos])
+			b.pos++
+		}
+	}
+	if n != 0 {
+		v %= n
+	}
+	return v
+}
+
+func (b *byteSource) Int63() int64 {
+	return int64(b.Uint64() >> 1)
+}
+
+func (b *byteSource) Seed(seed int64) {
+	b.pos = 0
+}
+
+// fuzzRand is the generator.Rand for generators in fuzz runs.
+// It offers generator.Chooser, so that the built-in generators take their numbers directly from the fuzz input.
+type fuzzRand struct {
+	f *fork
+}
+
+var _ generator.Chooser = fuzzRand{}
+
+func (r fuzzRand) Fork(name string) generator.Rand {
+	return r.f.Fork(name)
+}
+
+func (r fuzzRand) HasMore() bool {
+	return r.f.HasMore()
+}
+
+func (r fuzzRand) R() *rand.Rand {
+	return r.f.R()
+}
+
+func (r fuzzRand) Choose(n uint64) uint64 {
+	return r.f.parent.fuzzInput.choose(n)
+}
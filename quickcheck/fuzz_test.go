@@ -0,0 +1,109 @@
+package quickcheck
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/peterzeller/go-stateful-test/generator"
+	"github.com/peterzeller/go-stateful-test/pick"
+	"github.com/peterzeller/go-stateful-test/statefulTest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestByteSource(t *testing.T) {
+	src := newByteSource([]byte{0, 0, 0, 0, 0, 0, 0, 1, 0xff})
+	require.Equal(t, uint64(1), src.Uint64())
+	require.Equal(t, uint64(0xff)<<56, src.Uint64())
+	// exhausted sources return zeros
+	require.Equal(t, uint64(0), src.Uint64())
+	require.Equal(t, int64(0), src.Int63())
+
+	src = newByteSource([]byte{7, 0x03, 0xe8, 0xff})
+	require.Equal(t, uint64(0), src.choose(1))
+	require.Equal(t, uint64(7), src.choose(10))
+	// two bytes for numbers up to 1000
+	require.Equal(t, uint64(1000), src.choose(1001))
+	require.Equal(t, uint64(0xff%200), src.choose(200))
+	require.Equal(t, uint64(0), src.choose(0))
+}
+
+// recordT is a TestingT that records the log
+type recordT struct {
+	log    strings.Builder
+	failed bool
+}
+
+func (r *recordT) Errorf(format string, args ...interface{}) {
+	r.failed = true
+	r.Logf(format, args...)
+}
+
+func (r *recordT) FailNow() {
+	r.failed = true
+	panic(errTestFailed)
+}
+
+func (r *recordT) Failed() bool {
+	return r.failed
+}
+
+func (r *recordT) Logf(format string, args ...interface{}) {
+	r.log.WriteString(fmt.Sprintf(format, args...))
+	r.log.WriteString("\n")
+}
+
+func TestRunFuzzInputShrinks(t *testing.T) {
+	// the value is read from two bytes
+	data := []byte{0x03, 0xe8}
+	rt := &recordT{}
+	func() {
+		defer func() {
+			if r := recover(); r != nil && r != errTestFailed {
+				panic(r)
+			}
+		}()
+		runFuzzInput(rt, setDefaults(Config{}), data, func(t statefulTest.T) {
+			x := pick.Val(t, generator.IntRange(0, 1000))
+			t.Logf("x = %d", x)
+			require.Less(t, x, 10)
+		})
+	}()
+	require.True(t, rt.failed)
+	require.Contains(t, rt.log.String(), "x = 10\n")
+}
+
+func TestRunFuzzInputFork(t *testing.T) {
+	// a generator that draws its value from a fork
+	inner := generator.IntRange(0, 1000)
+	forking := &generator.AnonGenerator[int, int64]{
+		GenName: "Forking",
+		GenRandom: func(rnd generator.Rand, size int) int64 {
+			return inner.Random(rnd.Fork("inner"), size)
+		},
+		GenShrink:    inner.Shrink,
+		GenSize:      inner.Size,
+		GenRValue:    inner.RValue,
+		GenEnumerate: inner.Enumerate,
+	}
+	data := []byte{0x03, 0xe8}
+	var values []int
+	rt := &recordT{}
+	func() {
+		defer func() {
+			if r := recover(); r != nil && r != errTestFailed {
+				panic(r)
+			}
+		}()
+		runFuzzInput(rt, setDefaults(Config{}), data, func(t statefulTest.T) {
+			x := pick.Val[int, int64](t, forking)
+			values = append(values, x)
+			t.Logf("x = %d", x)
+			require.Less(t, x, 10)
+		})
+	}()
+	// the fork takes the value from the fuzz input
+	require.Equal(t, 1000, values[0])
+	require.True(t, rt.failed)
+	require.Contains(t, rt.log.String(), "x = 10\n")
+}
@@ -3,25 +3,122 @@ package quickcheck
 import (
 	"context"
 	"errors"
+	"fmt"
+	"math"
 	"runtime/debug"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/peterzeller/go-stateful-test/internal/reproducer"
 	"github.com/peterzeller/go-stateful-test/statefulTest"
+	"github.com/peterzeller/go-stateful-test/stats"
 )
 
 // Run the given testing function `f` multiple times.
 // Each run will be executed with different values in generators.
 // When an error is found, we try to shrink the test run before showing the final error.
 // Only the error message in the shrunk execution and the logs from this run will be shown.
+//
+// The seed used for the runs is printed together with the failure.
+// Setting Config.Seed (or the -quickcheck.seed flag) to this value replays the failing run and its shrinking.
+//
+// If the test has a name (like *testing.T), the shrunk counterexample is stored in a FailureDB under Config.FailureDBDir,
+// which is testdata/quickcheck in the package directory by default.
+// Stored counterexamples are replayed before the random runs, so that a failure found once stays covered.
 func Run(t TestingT, cfg Config, f func(t statefulTest.T)) {
 	cfg = setDefaults(cfg)
+	seed, err := resolveSeed(cfg)
+	if err != nil {
+		t.Errorf("quickcheck: %v", err)
+		t.FailNow()
+		return
+	}
+
+	runState := stateRunner(t, cfg, f)
+	db, testName, useDB := failureDB(t, cfg)
+
+	// replay stored counterexamples first
+	var s *state
+	var stored *Counterexample
+	if useDB {
+		s, stored = replayCounterexamples(t, cfg, db, testName, runState)
+	}
+	fromDB := s != nil
+	if s == nil {
+		var iteration int
+		var passed, discarded int
+		var labelStats stats.Stats
+		s, iteration, passed, discarded = runRandom(cfg, seed, runState, &labelStats)
+		if s == nil {
+			if passed < cfg.NumberOfRuns {
+				t.Errorf("quickcheck: gave up after %d passed and %d discarded test runs (MaxDiscardRatio = %d), use seed %d to reproduce",
+					passed, discarded, cfg.MaxDiscardRatio, seed)
+				t.FailNow()
+			} else if discarded > 0 {
+				t.Logf("quickcheck: %d test runs passed, %d discarded", passed, discarded)
+			}
+			reportStats(t, &labelStats)
+			return
+		}
+		t.Logf("Found error in run %d (seed = %d, size = %d), shrinking testcase ...", iteration, seed, s.size)
+	}
+
+	shrunkS := shrinkFailure(t, cfg, s, runState)
+
+	if shrunkS.failed {
+		if useDB {
+			err := db.Add(testName, newCounterexample(s, shrunkS))
+			if err != nil {
+				t.Logf("quickcheck: could not store counterexample: %v", err)
+			}
+		}
+		if !fromDB {
+			t.Logf("To reproduce this failure, use Config.Seed = %d or run with -quickcheck.seed=%d", seed, seed)
+		}
+		if cfg.Reproducer {
+			fallback := fmt.Sprintf("quickcheck.Run(t, quickcheck.Config{Seed: %d}, property)", seed)
+			if fromDB {
+				// the base seed of this call did not find the failure, so the first run replays the stored seed and size
+				fallback = fmt.Sprintf("quickcheck.Run(t, quickcheck.Config{Seed: %d, MinSize: %d, MaxSize: %d}, property)",
+					stored.Seed, stored.Size, stored.Size)
+			}
+			emitReproducer(t, cfg, shrunkS, fallback)
+		}
+		t.FailNow()
+	}
+}
 
-	count := 0
-	runState := func(s *state) (result *state) {
-		count++
+// emitReproducer logs a Go test function that replays the shrunk run s and stores it next to the FailureDB.
+// The fallback statement is used if the picked values cannot be written as Go literals.
+func emitReproducer(t TestingT, cfg Config, s *state, fallback string) {
+	testName := ""
+	named, isNamed := t.(interface{ Name() string })
+	if isNamed {
+		testName = named.Name()
+	}
+	src := reproducer.Generate(testName, "quickcheck.Run", s.steps, fallback)
+	t.Logf("Reproducer:\n%s", src)
+	if isNamed {
+		path, err := reproducer.Write(cfg.FailureDBDir, testName, src)
+		if err != nil {
+			t.Logf("quickcheck: could not store reproducer: %v", err)
+			return
+		}
+		t.Logf("Reproducer stored in %s", path)
+	}
+}
+
+// stateRunner returns a function that executes the test function f on a given state.
+// The returned function returns the state if the run failed and nil otherwise.
+// Discarded runs are not failures, so nil is returned for them as well.
+func stateRunner(t TestingT, cfg Config, f func(t statefulTest.T)) func(s *state) (result *state) {
+	var count int64
+	return func(s *state) (result *state) {
+		run := atomic.AddInt64(&count, 1)
 		defer func() {
 			if cfg.PrintAllLogs {
-				t.Logf("Test run %d (failed = %v):\n%s", count, s.failed, s.GetLog())
+				t.Logf("Test run %d (failed = %v):\n%s", run, s.failed, s.GetLog())
 			}
 		}()
 
@@ -34,6 +131,13 @@ func Run(t TestingT, cfg Config, f func(t statefulTest.T)) {
 						result = s
 						return
 					}
+					if errors.Is(err, errDiscarded) {
+						// errors reported before the discard still fail the run
+						if s.failed {
+							result = s
+						}
+						return
+					}
 				}
 
 				stackTrace := debug.Stack()
@@ -48,29 +152,69 @@ func Run(t TestingT, cfg Config, f func(t statefulTest.T)) {
 		}
 		return nil
 	}
-	var s *state = firstNotNil[state](cfg, func(iteration int) *state {
-		s := initState(cfg, int64(iteration))
-		defer s.runCleanups()
-		return runState(s)
-	})
-	if s == nil || !s.Failed() {
-		// all ok
-		return
-	}
-	t.Logf("Found error, shrinking testcase ...")
+}
 
-	// start shrinking:
+// shrinkFailure shrinks the failing state s and logs the shrunk test run.
+// Returns the shrunk state.
+// If the shrunk state does not fail, the failure could not be reproduced and the log of the last run is shown instead.
+func shrinkFailure(t TestingT, cfg Config, s *state, runState func(*state) *state) *state {
 	ctx, cancel := context.WithTimeout(context.Background(), cfg.MaxShrinkDuration)
 	defer cancel()
-	shrunkS := shrinkState(ctx, s, runState)
-
+	var shrunkS *state
+	if s.choices != nil {
+		shrunkS = shrinkChoices(ctx, s, runState)
+	} else {
+		shrunkS = shrinkState(ctx, s, runState)
+	}
 	if shrunkS.failed {
 		t.Logf("Shrunk Test Run:\n%s", shrunkS.GetLog())
-		t.FailNow()
 	} else {
 		// print original error
 		t.Logf("Could not reproduce error while shrinking (flaky test?)\n%s", shrunkS.GetLog())
 	}
+	return shrunkS
+}
+
+// replayCounterexamples runs the counterexamples stored for the test.
+// Returns the first failing run and its counterexample, or nil if all stored counterexamples pass.
+// Stale counterexamples, which do not match the test anymore, are reported and removed from the database.
+func replayCounterexamples(t TestingT, cfg Config, db FailureDB, testName string, runState func(*state) *state) (*state, *Counterexample) {
+	counterexamples, err := db.Load(testName)
+	if err != nil {
+		t.Logf("quickcheck: could not load stored counterexamples: %v", err)
+		return nil, nil
+	}
+	var stale []Counterexample
+	defer func() {
+		if len(stale) == 0 {
+			return
+		}
+		err := db.Prune(testName, func(c Counterexample) bool {
+			for _, s := range stale {
+				if s.sameRun(c) {
+					return false
+				}
+			}
+			return true
+		})
+		if err != nil {
+			t.Logf("quickcheck: could not remove stale counterexamples: %v", err)
+		}
+	}()
+	for _, c := range counterexamples {
+		s, isStale := replayCounterexample(cfg, c, runState)
+		if isStale {
+			t.Logf("quickcheck: removing stale counterexample from %s (seed = %d), because the test no longer draws the stored choices",
+				db.Path(testName), c.Seed)
+			stale = append(stale, c)
+			continue
+		}
+		if s.Failed() {
+			t.Logf("Stored counterexample from %s (seed = %d) still fails, shrinking testcase ...", db.Path(testName), c.Seed)
+			return s, &c
+		}
+	}
+	return nil, nil
 }
 
 func setDefaults(cfg Config) Config {
@@ -80,15 +224,120 @@ func setDefaults(cfg Config) Config {
 	if cfg.MaxShrinkDuration == 0 {
 		cfg.MaxShrinkDuration = 30 * time.Second
 	}
+	if cfg.MaxDiscardRatio == 0 {
+		cfg.MaxDiscardRatio = 10
+	}
+	if cfg.Parallelism <= 0 {
+		cfg.Parallelism = 1
+	}
+	if cfg.MaxSize == 0 {
+		cfg.MaxSize = 100
+	}
+	if cfg.SizeSchedule == nil {
+		cfg.SizeSchedule = LinearSizes
+	}
+	if cfg.FailureDBDir == "" {
+		cfg.FailureDBDir = DefaultFailureDBDir
+	}
 	return cfg
 }
 
-func firstNotNil[X any](cfg Config, f func(iteration int) *X) *X {
-	for i := 0; i < cfg.NumberOfRuns; i++ {
-		res := f(i)
-		if res != nil {
-			return res
+// reportStats logs the distribution of labels and fails the test if a label required by Cover is under-represented.
+func reportStats(t TestingT, labelStats *stats.Stats) {
+	if labelStats.Empty() {
+		return
+	}
+	t.Logf("%s", labelStats)
+	insufficient := labelStats.Insufficient()
+	for _, msg := range insufficient {
+		t.Errorf("%s", msg)
+	}
+	if len(insufficient) > 0 {
+		t.FailNow()
+	}
+}
+
+// runRandom executes test runs until cfg.NumberOfRuns runs passed, a run fails,
+// or more than cfg.MaxDiscardRatio runs per required run were discarded.
+// Run number i uses the seed seed+i, including discarded runs.
+// The labels of passed runs are recorded in labelStats.
+// Returns the failing state (or nil), the number of the failing run, and the number of passed and discarded runs.
+//
+// With cfg.Parallelism > 1, the runs are executed on several goroutines.
+// The results are evaluated in the order of the run numbers, so the result is the same as for a sequential execution.
+func runRandom(cfg Config, seed int64, runState func(*state) *state, labelStats *stats.Stats) (failed *state, iteration int, passed int, discarded int) {
+	maxDiscarded := cfg.MaxDiscardRatio * cfg.NumberOfRuns
+	var mu sync.Mutex
+	// results of finished runs that have not been evaluated yet
+	type runResult struct {
+		s      *state
+		failed bool
+	}
+	results := make(map[int]runResult)
+	// next run to start
+	next := 0
+	// runs at or after limit are not needed, because an earlier run failed
+	limit := math.MaxInt
+	done := false
+	// evaluate processes the finished runs in order until the result is known or a run is still missing
+	evaluate := func() {
+		for !done {
+			r, ok := results[iteration]
+			if !ok {
+				return
+			}
+			delete(results, iteration)
+			s := r.s
+			if r.failed {
+				failed = s
+				done = true
+				return
+			}
+			if s.discarded {
+				discarded++
+			} else {
+				passed++
+				labelStats.Record(&s.labels)
+			}
+			iteration++
+			if passed >= cfg.NumberOfRuns || discarded > maxDiscarded {
+				done = true
+			}
 		}
 	}
-	return nil
+	worker := func() {
+		for {
+			mu.Lock()
+			if done || next >= limit {
+				mu.Unlock()
+				return
+			}
+			i := next
+			next++
+			mu.Unlock()
+
+			s := newState(cfg, seed+int64(i), cfg.size(i))
+			res := runState(s)
+			s.runCleanups()
+
+			mu.Lock()
+			results[i] = runResult{s: s, failed: res != nil}
+			if res != nil && i < limit {
+				limit = i
+			}
+			evaluate()
+			mu.Unlock()
+		}
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < cfg.Parallelism; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			worker()
+		}()
+	}
+	wg.Wait()
+	return failed, iteration, passed, discarded
 }
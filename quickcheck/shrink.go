@@ -10,6 +10,8 @@ import (
 	"github.com/peterzeller/go-stateful-test/quickcheck/tree"
 )
 
+// shrinkState repeatedly shrinks the given failing state.
+// It returns the smallest failing state found.
 func shrinkState(ctx context.Context, s *state, runState func(*state) (result *state)) *state {
 	for ctx.Err() == nil {
 		s2 := shrinkOne(ctx, s, runState)
@@ -34,7 +36,7 @@ func shrinkOne(ctx context.Context, s *state, runState func(*state) (result *sta
 			return s
 		}
 		// Try to run with current shrink:
-		s2 := initState(s.cfg, s.mainFork.genTree.Seed)
+		s2 := s.restart()
 		s2.mainFork.presetTree = currentShrink
 		res := runState(s2)
 		s2.runCleanups()
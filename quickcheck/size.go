@@ -0,0 +1,48 @@
+package quickcheck
+
+import "math"
+
+// SizeSchedule computes the size for the test run with the given number.
+// Runs after numberOfRuns (because of discarded runs) should use the size of the last run.
+// The result must be between minSize and maxSize.
+type SizeSchedule func(run, numberOfRuns, minSize, maxSize int) int
+
+// LinearSizes increases the size linearly from minSize in the first run to maxSize in the last run.
+func LinearSizes(run, numberOfRuns, minSize, maxSize int) int {
+	if run >= numberOfRuns-1 {
+		return maxSize
+	}
+	return minSize + (maxSize-minSize)*run/(numberOfRuns-1)
+}
+
+// ExponentialSizes increases the size exponentially from minSize in the first run to maxSize in the last run,
+// so that most runs use small sizes.
+func ExponentialSizes(run, numberOfRuns, minSize, maxSize int) int {
+	if run >= numberOfRuns-1 {
+		return maxSize
+	}
+	progress := float64(run) / float64(numberOfRuns-1)
+	return minSize + int(math.Pow(float64(maxSize-minSize+1), progress)) - 1
+}
+
+// ConstantSize uses maxSize for all runs.
+func ConstantSize(run, numberOfRuns, minSize, maxSize int) int {
+	return maxSize
+}
+
+// size returns the size for the given run according to the configured schedule.
+// The size is at least 1, because some generators need a positive size to produce values.
+func (cfg Config) size(run int) int {
+	minSize := cfg.MinSize
+	if minSize < 1 {
+		minSize = 1
+	}
+	size := cfg.SizeSchedule(run, cfg.NumberOfRuns, minSize, cfg.MaxSize)
+	if size < minSize {
+		return minSize
+	}
+	if size > cfg.MaxSize {
+		return cfg.MaxSize
+	}
+	return size
+}
@@ -0,0 +1,26 @@
+package quickcheck
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSizeSchedules(t *testing.T) {
+	sizes := func(schedule SizeSchedule) []int {
+		cfg := setDefaults(Config{NumberOfRuns: 5, MinSize: 1, MaxSize: 17, SizeSchedule: schedule})
+		var res []int
+		for run := 0; run < 7; run++ {
+			res = append(res, cfg.size(run))
+		}
+		return res
+	}
+	require.Equal(t, []int{1, 5, 9, 13, 17, 17, 17}, sizes(LinearSizes))
+	require.Equal(t, []int{1, 2, 4, 8, 17, 17, 17}, sizes(ExponentialSizes))
+	require.Equal(t, []int{17, 17, 17, 17, 17, 17, 17}, sizes(ConstantSize))
+
+	// the default minimum size is 1
+	cfg := setDefaults(Config{NumberOfRuns: 5, MaxSize: 9})
+	require.Equal(t, 1, cfg.size(0))
+	require.Equal(t, 9, cfg.size(4))
+}
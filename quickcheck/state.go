@@ -10,8 +10,11 @@ import (
 
 	"github.com/peterzeller/go-fun/list/linked"
 	"github.com/peterzeller/go-stateful-test/generator"
+	"github.com/peterzeller/go-stateful-test/internal/callsite"
+	"github.com/peterzeller/go-stateful-test/internal/reproducer"
 	"github.com/peterzeller/go-stateful-test/quickcheck/tree"
 	"github.com/peterzeller/go-stateful-test/statefulTest"
+	"github.com/peterzeller/go-stateful-test/stats"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -20,11 +23,29 @@ type state struct {
 	mainFork *fork
 	// initialized to false and set to true when the test has failed
 	failed bool
+	// set to true when the test run was discarded
+	discarded bool
 	// buffer for log messages.
 	// As we only want to print the log for the last failed test run, we cannot write directly to standard out.
 	log     strings.Builder
 	cleanup []func()
 	cfg     Config
+	// source creates the source of random values for the main fork
+	source func(seed int64) rand.Source
+	// labels added to this test run
+	labels stats.Labels
+	// choices records the random choices when using the ChoiceSequenceEngine (nil otherwise)
+	choices *choiceSource
+	// recording records the random values of the main fork with the GenTreeEngine (nil otherwise)
+	recording *recordingSource
+	// fuzzInput is the input of the fuzzer in fuzz runs (nil otherwise)
+	fuzzInput *byteSource
+	// size of the test run
+	size int
+	// steps of the test run, for generating a reproducer
+	steps []reproducer.Step
+	// functions marked with Helper
+	helpers callsite.Helpers
 }
 
 func (s *state) Cleanup(f func()) {
@@ -95,6 +116,14 @@ func (f forkGenerator) RValue(rv *fork) (*fork, bool) {
 }
 
 func (f *fork) Fork(name string) generator.Rand {
+	if f.parent.choices != nil {
+		// all forks draw from the same choice sequence
+		return f
+	}
+	if f.parent.fuzzInput != nil {
+		// all forks draw from the fuzz input, so that the fuzzer controls the values inside forks as well
+		return fuzzRand{f}
+	}
 	gen := generator.ToUntyped[*fork, *fork](forkGenerator{name: name, origin: f})
 	child := f.PickValue(gen).Value.(*fork)
 	return child
@@ -105,6 +134,9 @@ func (f *fork) R() *rand.Rand {
 }
 
 func (f *fork) PickValue(gen generator.UntypedGenerator) generator.UV {
+	if f.parent.choices != nil {
+		return f.pickChoice(gen)
+	}
 	// check if we have a preset value in the presetTree
 	genName := gen.Name()
 	var picked tree.GeneratedValue
@@ -122,7 +154,11 @@ func (f *fork) PickValue(gen generator.UntypedGenerator) generator.UV {
 	}
 	if !foundPreset {
 		// generate new random value
-		v := gen.Random(f, f.maxSize)
+		var rnd generator.Rand = f
+		if f.parent.fuzzInput != nil {
+			rnd = fuzzRand{f}
+		}
+		v := gen.Random(rnd, f.maxSize)
 		picked = tree.GeneratedValue{
 			Generator: gen,
 			Value:     v,
@@ -153,6 +189,11 @@ func (f *fork) HasMore() bool {
 			old := f.presetTree
 			f.presetTree = tree.New(old.GeneratedValues().Tail())
 		}
+	} else if f.parent.choices != nil {
+		// lower choices stop the loop, so that shrinking makes loops shorter
+		result = f.parent.choices.choose(uint64(f.maxSize)+1) > 1
+	} else if f.parent.fuzzInput != nil {
+		result = f.parent.fuzzInput.choose(uint64(f.maxSize)+1) > 1
 	} else {
 		if f.genTree.Rand.Float64()*float64(f.maxSize) > 1 {
 			result = true
@@ -170,31 +211,44 @@ func (f *fork) Size() *big.Int {
 }
 
 func (s *state) PickValue(gen generator.UntypedGenerator) generator.UV {
-	return s.mainFork.PickValue(gen)
+	v := s.mainFork.PickValue(gen)
+	s.steps = append(s.steps, reproducer.Value(v.Value))
+	return v
 }
 
 func (s *state) HasMore() bool {
-	return s.mainFork.HasMore()
+	b := s.mainFork.HasMore()
+	s.steps = append(s.steps, reproducer.HasMore(b))
+	return b
 }
 
 func (s *state) Logf(format string, args ...any) {
+	msg := s.helpers.Prefix() + fmt.Sprintf(format, args...)
 	if s.cfg.PrintAllLogs {
-		fmt.Printf(format, args...)
-		fmt.Printf("\n")
+		fmt.Println(msg)
 		return
 	}
-	// TODO implement like in real Log and add source code line to message?
-	_, _ = fmt.Fprintf(&s.log, format, args...)
+	s.log.WriteString(msg)
 	s.log.WriteRune('\n')
 }
 
+// Helper marks the calling function as a test helper, like testing.T.Helper.
+// Log messages then show the location of the caller of the helper.
+func (s *state) Helper() {
+	s.helpers.Mark()
+}
+
 var _ statefulTest.T = &state{}
 var _ assert.TestingT = &state{}
 var _ require.TestingT = &state{}
 
 func (s *state) Errorf(format string, args ...interface{}) {
 	s.failed = true
-	_, _ = fmt.Fprintf(&s.log, format, args...)
+	msg := callsite.TrimTrace(fmt.Sprintf(format, args...))
+	s.log.WriteString(s.helpers.Prefix() + msg)
+	if !strings.HasSuffix(msg, "\n") {
+		s.log.WriteRune('\n')
+	}
 }
 
 var errTestFailed = fmt.Errorf("test failed")
@@ -204,6 +258,40 @@ func (s *state) FailNow() {
 	panic(errTestFailed)
 }
 
+var errDiscarded = fmt.Errorf("test run discarded")
+
+func (s *state) Assume(cond bool) {
+	if !cond {
+		s.Discard()
+	}
+}
+
+func (s *state) Discard() {
+	s.discarded = true
+	panic(errDiscarded)
+}
+
+func (s *state) Label(labels ...string) {
+	for _, l := range labels {
+		s.labels.Add(l)
+	}
+}
+
+func (s *state) Classify(cond bool, label string) {
+	if cond {
+		s.labels.Add(label)
+	}
+}
+
+func (s *state) Collect(value interface{}) {
+	s.labels.Add(fmt.Sprint(value))
+}
+
+func (s *state) Cover(minPercent float64, cond bool, label string) {
+	s.labels.Require(label, minPercent)
+	s.Classify(cond, label)
+}
+
 func (s *state) Failed() bool {
 	return s.failed
 }
@@ -219,18 +307,39 @@ func (s *state) runCleanups() {
 	s.cleanup = nil
 }
 
-func initState(cfg Config, seed int64) *state {
+func initState(cfg Config, seed int64, size int) *state {
+	return initStateFromSource(cfg, seed, size, func(seed int64) rand.Source {
+		return &recordingSource{random: rand.NewSource(seed).(rand.Source64)}
+	})
+}
+
+// initStateFromSource creates a new state where the main fork takes its random values from the given source.
+func initStateFromSource(cfg Config, seed int64, size int, source func(seed int64) rand.Source) *state {
+	src := source(seed)
 	s := &state{
 		mainFork: &fork{
 			parent:     nil,
-			genTree:    tree.NewGenNode(seed),
+			genTree:    tree.NewGenNodeFromSource(seed, src),
 			presetTree: nil,
-			maxSize:    100, // TODO init differently
+			maxSize:    size,
 		},
 		failed: false,
 		log:    strings.Builder{},
 		cfg:    cfg,
+		source: source,
+		size:   size,
 	}
 	s.mainFork.parent = s
+	switch src := src.(type) {
+	case *recordingSource:
+		s.recording = src
+	case *byteSource:
+		s.fuzzInput = src
+	}
 	return s
 }
+
+// restart creates a fresh state that uses the same seed and source of random values as s.
+func (s *state) restart() *state {
+	return initStateFromSource(s.cfg, s.mainFork.genTree.Seed, s.size, s.source)
+}
@@ -31,9 +31,14 @@ func (v GeneratedValue) Size() *big.Int {
 }
 
 func NewGenNode(seed int64) *MutableGenNode {
+	return NewGenNodeFromSource(seed, rand.NewSource(seed))
+}
+
+// NewGenNodeFromSource creates a new node that takes its random values from the given source.
+func NewGenNodeFromSource(seed int64, src rand.Source) *MutableGenNode {
 	return &MutableGenNode{
 		GeneratedValues: [][]GeneratedValue{{}},
-		Rand:            rand.New(rand.NewSource(seed)),
+		Rand:            rand.New(src),
 		Seed:            seed,
 	}
 }
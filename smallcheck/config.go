@@ -1,5 +1,13 @@
 package smallcheck
 
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
 type Config struct {
 	// maximum depth to explore
 	Depth int
@@ -7,15 +15,82 @@ type Config struct {
 	PrintAllLogs bool
 	// print the logs directly, not just after a run
 	PrintLiveLogs bool
+	// maximum number of discarded runs per passed run at each depth (default 10)
+	MaxDiscardRatio int
+	// Parallelism is the number of goroutines that explore the search tree concurrently (default: 1).
+	// The test function must only share thread-safe resources between runs.
+	Parallelism int
+	// SplitDepth is the number of choice points that determine the subtree of a run (default: 2).
+	// The subtrees are distributed over the goroutines and shards.
+	// The subtree of a run is only known when the test function reaches the split point,
+	// so each goroutine runs the test function up to the split point once for every subtree of the other goroutines and shards.
+	// Expensive setup before the split point is therefore repeated Parallelism * ShardCount times per subtree.
+	SplitDepth int
+	// ShardIndex and ShardCount split the exploration over several processes.
+	// Each process only explores the subtrees with ShardIndex = n % ShardCount.
+	// If ShardCount is 0, the values are taken from the environment variables SMALLCHECK_SHARD_INDEX and SMALLCHECK_SHARD_COUNT.
+	ShardIndex int
+	ShardCount int
+	// MaxDuration limits the time spent exploring (0 = no limit).
+	// When the limit is reached, smallcheck stops and reports how far it got, without failing the test.
+	MaxDuration time.Duration
+	// MaxRuns limits the number of test runs over all depths (0 = no limit), like MaxDuration.
+	MaxRuns int
+	// Reproducer enables printing a Go test function that replays the failing run with pick.Replay.
+	// If the test has a name, the test function is also stored in ReproducerDir.
+	Reproducer bool
+	// ReproducerDir is the directory for storing reproducers (default: testdata/smallcheck).
+	ReproducerDir string
 }
 
 func setDefaults(cfg Config) Config {
 	if cfg.Depth == 0 {
 		cfg.Depth = 5
 	}
+	if cfg.MaxDiscardRatio == 0 {
+		cfg.MaxDiscardRatio = 10
+	}
+	if cfg.Parallelism <= 0 {
+		cfg.Parallelism = 1
+	}
+	if cfg.SplitDepth <= 0 {
+		cfg.SplitDepth = 2
+	}
+	if cfg.ReproducerDir == "" {
+		cfg.ReproducerDir = filepath.Join("testdata", "smallcheck")
+	}
 	return cfg
 }
 
+const (
+	shardIndexEnvVar = "SMALLCHECK_SHARD_INDEX"
+	shardCountEnvVar = "SMALLCHECK_SHARD_COUNT"
+)
+
+// resolveShard determines the shard of the search tree to explore in this process.
+func resolveShard(cfg Config) (index int, count int, err error) {
+	index, count = cfg.ShardIndex, cfg.ShardCount
+	if count == 0 {
+		if env := os.Getenv(shardCountEnvVar); env != "" {
+			count, err = strconv.Atoi(env)
+			if err != nil {
+				return 0, 0, fmt.Errorf("invalid value for %s: %w", shardCountEnvVar, err)
+			}
+			index, err = strconv.Atoi(os.Getenv(shardIndexEnvVar))
+			if err != nil {
+				return 0, 0, fmt.Errorf("invalid value for %s: %w", shardIndexEnvVar, err)
+			}
+		}
+	}
+	if count == 0 {
+		return 0, 1, nil
+	}
+	if count < 0 || index < 0 || index >= count {
+		return 0, 0, fmt.Errorf("invalid shard %d of %d", index, count)
+	}
+	return index, count, nil
+}
+
 type TestingT interface {
 	Errorf(format string, args ...interface{})
 	FailNow()
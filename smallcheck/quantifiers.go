@@ -0,0 +1,135 @@
+package smallcheck
+
+import (
+	"fmt"
+
+	"github.com/peterzeller/go-stateful-test/generator"
+	"github.com/peterzeller/go-stateful-test/statefulTest"
+)
+
+// defaultDepth is the enumeration depth for quantifiers that are used outside of smallcheck
+var defaultDepth = setDefaults(Config{}).Depth
+
+// Exists checks that pred holds for at least one value of gen.
+// The values are enumerated exhaustively up to the depth of the current smallcheck run,
+// and the test fails if no value satisfies pred.
+// Before the final depth, a run is discarded instead if the enumeration was not exhaustive,
+// because a witness might exist at a larger depth.
+// Returns the first value that satisfies pred.
+func Exists[T, R any](t statefulTest.T, gen generator.Generator[T, R], pred func(v T) bool) (T, bool) {
+	var witness T
+	found := false
+	depth, exhaustive := enumerate(t, gen, func(v T) bool {
+		if pred(v) {
+			witness = v
+			found = true
+			return false
+		}
+		return true
+	})
+	if !found {
+		discardIfInconclusive(t, exhaustive)
+		t.Errorf("Exists: no inner value of %s found up to depth %d%s", gen.Name(), depth, outerValues(t))
+	}
+	return witness, found
+}
+
+// ExistsUnique checks that pred holds for exactly one value of gen.
+// Like Exists, the values are enumerated up to the depth of the current smallcheck run.
+// Returns the value that satisfies pred.
+func ExistsUnique[T, R any](t statefulTest.T, gen generator.Generator[T, R], pred func(v T) bool) (T, bool) {
+	var witnesses []T
+	depth, exhaustive := enumerate(t, gen, func(v T) bool {
+		if pred(v) {
+			witnesses = append(witnesses, v)
+		}
+		return len(witnesses) < 2
+	})
+	switch len(witnesses) {
+	case 0:
+		discardIfInconclusive(t, exhaustive)
+		var zero T
+		t.Errorf("ExistsUnique: no inner value of %s found up to depth %d%s", gen.Name(), depth, outerValues(t))
+		return zero, false
+	case 1:
+		return witnesses[0], true
+	default:
+		t.Errorf("ExistsUnique: found several inner values of %s: %v and %v%s", gen.Name(), witnesses[0], witnesses[1], outerValues(t))
+		return witnesses[0], false
+	}
+}
+
+// ForAll checks that pred holds for all values of gen.
+// Like Exists, the values are enumerated up to the depth of the current smallcheck run.
+// The test fails with the first value that does not satisfy pred.
+func ForAll[T, R any](t statefulTest.T, gen generator.Generator[T, R], pred func(v T) bool) bool {
+	var counterexample T
+	failed := false
+	enumerate(t, gen, func(v T) bool {
+		if !pred(v) {
+			counterexample = v
+			failed = true
+			return false
+		}
+		return true
+	})
+	if failed {
+		t.Errorf("ForAll: predicate does not hold for inner value %v of %s%s", counterexample, gen.Name(), outerValues(t))
+	}
+	return !failed
+}
+
+// enumerate calls f for the values of gen up to the depth of the current run, until f returns false.
+// If the enumeration is not exhaustive, the current run is marked as non-exhaustive.
+// Returns the depth used for the enumeration and whether all values were enumerated.
+func enumerate[T, R any](t statefulTest.T, gen generator.Generator[T, R], f func(v T) bool) (int, bool) {
+	depth := defaultDepth
+	s, isState := t.(*state)
+	if isState {
+		depth = s.parent.maxDepth
+	}
+	it := gen.Enumerate(depth).Iterator()
+	for {
+		next := it.Next()
+		if !next.Present() {
+			if isState && !next.Exhaustive() {
+				s.parent.markNonExhaustive(gen.Name())
+			}
+			return depth, next.Exhaustive()
+		}
+		v, ok := gen.RValue(next.Value())
+		if !ok {
+			continue
+		}
+		if !f(v) {
+			return depth, false
+		}
+	}
+}
+
+// discardIfInconclusive discards the current smallcheck run if no witness was found in a non-exhaustive enumeration
+// and a later depth will be explored.
+func discardIfInconclusive(t statefulTest.T, exhaustive bool) {
+	s, ok := t.(*state)
+	if ok && !exhaustive && s.parent.maxDepth < s.parent.cfg.Depth-1 {
+		s.Discard()
+	}
+}
+
+// outerValues describes the values picked so far in the current run, which are the witnesses for the outer quantifiers.
+func outerValues(t statefulTest.T) string {
+	s, ok := t.(*state)
+	if !ok {
+		return ""
+	}
+	var picked []interface{}
+	for _, step := range s.steps {
+		if !step.IsHasMore {
+			picked = append(picked, step.Value)
+		}
+	}
+	if len(picked) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (outer values: %v)", picked)
+}
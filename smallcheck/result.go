@@ -0,0 +1,79 @@
+package smallcheck
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Result summarizes a call to RunWithResult.
+type Result struct {
+	// Depths contains the statistics for each explored depth, starting with depth 1
+	Depths []DepthResult
+	// Failed is true if the test failed
+	Failed bool
+	// Exhaustive is true if the last explored depth covered all possible test runs
+	Exhaustive bool
+	// BudgetExhausted is true if MaxRuns or MaxDuration stopped the exploration
+	BudgetExhausted bool
+}
+
+// DepthResult contains the statistics for exploring one depth.
+type DepthResult struct {
+	Depth int
+	// Runs is the number of executed test runs
+	Runs int
+	// Passed is the number of test runs that passed
+	Passed int
+	// Discarded is the number of test runs discarded with Assume or Discard
+	Discarded int
+	// EmptyIterators is the number of test runs aborted because a generator had no values
+	EmptyIterators int
+	// NonExhaustive contains the names of the generators that were not enumerated exhaustively (sorted).
+	// "HasMore" is included when a HasMore loop was cut off at the depth.
+	NonExhaustive []string
+	// Exhaustive is true if all test runs up to the depth were explored
+	Exhaustive bool
+	// Complete is false if the depth was only partially explored, because a test run failed or the budget was exhausted
+	Complete bool
+	// Duration is the wall time for exploring the depth
+	Duration time.Duration
+}
+
+// Runs returns the total number of executed test runs.
+func (r Result) Runs() int {
+	runs := 0
+	for _, d := range r.Depths {
+		runs += d.Runs
+	}
+	return runs
+}
+
+// String formats the per-depth statistics as a table.
+func (r Result) String() string {
+	var b strings.Builder
+	b.WriteString("smallcheck depths:\n")
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', tabwriter.AlignRight)
+	_, _ = fmt.Fprintf(w, "depth\truns\tpassed\tdiscarded\tempty\ttime\t\n")
+	for _, d := range r.Depths {
+		_, _ = fmt.Fprintf(w, "%d\t%d\t%d\t%d\t%d\t%v\t  %s\n",
+			d.Depth, d.Runs, d.Passed, d.Discarded, d.EmptyIterators, d.Duration.Round(time.Microsecond), d.describe())
+	}
+	_ = w.Flush()
+	return b.String()
+}
+
+// describe returns the last column of the table
+func (d DepthResult) describe() string {
+	var parts []string
+	if !d.Complete {
+		parts = append(parts, "partial")
+	}
+	if d.Exhaustive {
+		parts = append(parts, "exhaustive")
+	} else if len(d.NonExhaustive) > 0 {
+		parts = append(parts, "non-exhaustive: "+strings.Join(d.NonExhaustive, ", "))
+	}
+	return strings.Join(parts, ", ")
+}
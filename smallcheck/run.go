@@ -3,12 +3,30 @@ package smallcheck
 import (
 	"errors"
 	"fmt"
+	"github.com/peterzeller/go-stateful-test/internal/reproducer"
 	"github.com/peterzeller/go-stateful-test/statefulTest"
+	"github.com/peterzeller/go-stateful-test/stats"
+	"math"
 	"runtime/debug"
+	"sort"
+	"sync"
+	"time"
 )
 
 func Run(t TestingT, cfg Config, f func(t statefulTest.T)) {
+	RunWithResult(t, cfg, f)
+}
+
+// RunWithResult is like Run, but also returns statistics about the explored depths.
+// The statistics are logged as a table as well.
+func RunWithResult(t TestingT, cfg Config, f func(t statefulTest.T)) (result Result) {
 	cfg = setDefaults(cfg)
+	shardIndex, shardCount, err := resolveShard(cfg)
+	if err != nil {
+		t.Errorf("smallcheck: %v", err)
+		result.Failed = true
+		return
+	}
 
 	runState := func(s *state) {
 		defer func() {
@@ -20,10 +38,20 @@ func Run(t TestingT, cfg Config, f func(t statefulTest.T)) {
 						s.failed = true
 						return
 					}
+					if errors.Is(err, errDiscarded) {
+						// errors reported before the discard still fail the run
+						return
+					}
 				}
 				if _, ok := r.(emptyIterator); ok {
 					// ignore error and just continue with next iteration
 					s.failed = false
+					s.emptyIterator = true
+					return
+				}
+				if _, ok := r.(skippedSubtree); ok {
+					s.failed = false
+					s.skipped = true
 					return
 				}
 
@@ -36,7 +64,111 @@ func Run(t TestingT, cfg Config, f func(t statefulTest.T)) {
 		f(s)
 	}
 
+	defer func() {
+		if len(result.Depths) > 0 {
+			t.Logf("%s", result)
+		}
+	}()
+	b := newBudget(cfg)
+	start := time.Now()
+	// exhaustiveness of the last completed depth
+	completedExhaustive := false
 	for depth := 1; depth < cfg.Depth; depth++ {
+		depthStart := time.Now()
+		res := exploreDepth(cfg, depth, shardIndex, shardCount, b, runState)
+		result.Depths = append(result.Depths, res.summary(depth, time.Since(depthStart)))
+		s := res.failedState
+		if s != nil && s.failed {
+			t.Errorf("Test failed at depth %d:\n%s", depth, s.GetLog())
+			if cfg.Reproducer {
+				emitReproducer(t, cfg, s)
+			}
+			result.Failed = true
+			return
+		}
+		if res.budgetExhausted {
+			reportBudgetExhausted(t, depth, completedExhaustive, b.runs, time.Since(start))
+			result.BudgetExhausted = true
+			return
+		}
+		completedExhaustive = res.runIsExhaustive
+		// with several shards, each process only knows whether its own part of the search tree is exhaustive
+		if (!res.runIsExhaustive || shardCount > 1) && depth < cfg.Depth-1 {
+			continue
+		}
+		// small depths often contain only few valid inputs, so the discard ratio is only checked at the final depth
+		if res.discarded > cfg.MaxDiscardRatio*res.passed {
+			t.Errorf("Gave up at depth %d: %d test runs passed, %d discarded (MaxDiscardRatio = %d)",
+				depth, res.passed, res.discarded, cfg.MaxDiscardRatio)
+			result.Failed = true
+			return
+		}
+		if res.runIsExhaustive {
+			t.Logf("run is exhaustive with depth = %d", depth)
+			result.Exhaustive = true
+		}
+		result.Failed = !reportStats(t, &res.labelStats)
+		return
+	}
+	return
+}
+
+// emitReproducer logs a Go test function that replays the failed run s and stores it in cfg.ReproducerDir.
+func emitReproducer(t TestingT, cfg Config, s *state) {
+	testName := ""
+	named, isNamed := t.(interface{ Name() string })
+	if isNamed {
+		testName = named.Name()
+	}
+	src := reproducer.Generate(testName, "smallcheck.Run", s.steps,
+		fmt.Sprintf("smallcheck.Run(t, smallcheck.Config{Depth: %d}, property)", cfg.Depth))
+	t.Logf("Reproducer:\n%s", src)
+	if isNamed {
+		path, err := reproducer.Write(cfg.ReproducerDir, testName, src)
+		if err != nil {
+			t.Logf("smallcheck: could not store reproducer: %v", err)
+			return
+		}
+		t.Logf("Reproducer stored in %s", path)
+	}
+}
+
+// depthResult combines the results of exploring the search tree up to one depth on several goroutines.
+type depthResult struct {
+	// the failed run with the smallest unit, or nil if no run failed
+	failedState *state
+	failedUnit  int
+	// false if some iterator was not exhaustive
+	runIsExhaustive bool
+	// true if the depth was only partially explored, because the budget was exhausted
+	budgetExhausted bool
+	// names of the generators that were not enumerated exhaustively
+	nonExhaustive map[string]bool
+	// number of runs aborted because a generator had no values
+	emptyIterators int
+	// number of passed and discarded runs
+	passed, discarded int
+	// labels of the passed runs
+	labelStats stats.Stats
+}
+
+// exploreDepth explores the part of the search tree up to the given depth that belongs to the shard.
+// The units of the shard are distributed over cfg.Parallelism goroutines.
+// If several runs fail, the failure in the smallest unit is returned, which is the first failure in depth-first order.
+func exploreDepth(cfg Config, depth int, shardIndex int, shardCount int, b *budget, runState func(s *state)) *depthResult {
+	res := &depthResult{
+		failedUnit:      math.MaxInt,
+		runIsExhaustive: true,
+		nonExhaustive:   make(map[string]bool),
+	}
+	var mu sync.Mutex
+	stop := func(unit int) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return unit > res.failedUnit
+	}
+	var wg sync.WaitGroup
+	for w := 0; w < cfg.Parallelism; w++ {
 		rs := &rState{
 			stack:           nil,
 			continueAtDepth: 0,
@@ -44,18 +176,90 @@ func Run(t TestingT, cfg Config, f func(t statefulTest.T)) {
 			done:            false,
 			cfg:             cfg,
 			runIsExhaustive: true,
+			partition: partition{
+				splitDepth: cfg.SplitDepth,
+				index:      shardIndex*cfg.Parallelism + w,
+				count:      shardCount * cfg.Parallelism,
+			},
+			stop:   stop,
+			budget: b,
 		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s := rs.exploreStates(runState)
 
-		s := rs.exploreStates(runState)
-		if s != nil && s.failed {
-			t.Errorf("Test failed at depth %d:\n%s", depth, s.GetLog())
-			return
-		}
-		if rs.runIsExhaustive {
-			t.Logf("run is exhaustive with depth = %d", depth)
-			return
-		}
+			mu.Lock()
+			defer mu.Unlock()
+			if s != nil && rs.unit < res.failedUnit {
+				res.failedState = s
+				res.failedUnit = rs.unit
+			}
+			res.runIsExhaustive = res.runIsExhaustive && rs.runIsExhaustive
+			res.budgetExhausted = res.budgetExhausted || rs.budgetExhausted
+			res.passed += rs.passed
+			res.discarded += rs.discarded
+			res.emptyIterators += rs.emptyIterators
+			for name := range rs.nonExhaustive {
+				res.nonExhaustive[name] = true
+			}
+			res.labelStats.Merge(&rs.labelStats)
+		}()
+	}
+	wg.Wait()
+	return res
+}
+
+// summary converts the result to the exported DepthResult
+func (res *depthResult) summary(depth int, duration time.Duration) DepthResult {
+	runs := res.passed + res.discarded + res.emptyIterators
+	if res.failedState != nil {
+		runs++
+	}
+	var nonExhaustive []string
+	for name := range res.nonExhaustive {
+		nonExhaustive = append(nonExhaustive, name)
+	}
+	sort.Strings(nonExhaustive)
+	complete := res.failedState == nil && !res.budgetExhausted
+	return DepthResult{
+		Depth:          depth,
+		Runs:           runs,
+		Passed:         res.passed,
+		Discarded:      res.discarded,
+		EmptyIterators: res.emptyIterators,
+		NonExhaustive:  nonExhaustive,
+		Exhaustive:     res.runIsExhaustive && complete,
+		Complete:       complete,
+		Duration:       duration,
+	}
+}
+
+// reportBudgetExhausted logs how far the exploration got when MaxRuns or MaxDuration stopped it.
+// This does not fail the test.
+func reportBudgetExhausted(t TestingT, depth int, completedExhaustive bool, runs int64, duration time.Duration) {
+	completed := "no depth was completed"
+	if depth > 1 {
+		completed = fmt.Sprintf("completed depth %d (exhaustive = %v)", depth-1, completedExhaustive)
 	}
+	t.Logf("smallcheck: budget exhausted after %d test runs in %v, %s, depth %d was partially explored",
+		runs, duration.Round(time.Millisecond), completed, depth)
+}
+
+// reportStats logs the distribution of labels and fails the test if a label required by Cover is under-represented.
+// Returns false if the test failed.
+func reportStats(t TestingT, labelStats *stats.Stats) bool {
+	if labelStats.Empty() {
+		return true
+	}
+	t.Logf("%s", labelStats)
+	insufficient := labelStats.Insufficient()
+	for _, msg := range insufficient {
+		t.Errorf("%s", msg)
+	}
+	return len(insufficient) == 0
 }
 
 var errTestFailed = fmt.Errorf("test failed")
+
+var errDiscarded = fmt.Errorf("test run discarded")
@@ -4,7 +4,12 @@ import (
 	"fmt"
 	"github.com/peterzeller/go-stateful-test/generator"
 	"github.com/peterzeller/go-stateful-test/generator/geniterable"
+	"github.com/peterzeller/go-stateful-test/internal/callsite"
+	"github.com/peterzeller/go-stateful-test/internal/reproducer"
+	"github.com/peterzeller/go-stateful-test/stats"
 	"strings"
+	"sync/atomic"
+	"time"
 )
 
 // rState is the state over several runs
@@ -16,10 +21,83 @@ type rState struct {
 	cfg             Config
 	// runIsExhaustive is initially true, and is set to false when we start an iterator that does not exhaustively cover all cases
 	runIsExhaustive bool
+	// names of the generators that were not enumerated exhaustively
+	nonExhaustive map[string]bool
+	// number of passed and discarded runs, and runs aborted because a generator had no values
+	passed, discarded, emptyIterators int
+	// labels of the passed runs
+	labelStats stats.Stats
+	// partition of the search tree that is explored by this rState
+	partition partition
+	// unit is the number of the current subtree in depth-first order (see partition)
+	unit int
+	// stop is called before each run and returns true if the exploration can stop,
+	// because a failure was found in an earlier unit (optional)
+	stop func(unit int) bool
+	// budget limits the number of runs and the time (optional)
+	budget *budget
+	// set to true when the exploration stopped because the budget was exhausted
+	budgetExhausted bool
 }
 
+// budget limits the exploration over all depths and goroutines.
+type budget struct {
+	// maximum number of runs (0 = no limit)
+	maxRuns int64
+	// deadline for starting new runs (zero = no limit)
+	deadline time.Time
+	// number of runs executed so far
+	runs int64
+}
+
+func newBudget(cfg Config) *budget {
+	b := &budget{maxRuns: int64(cfg.MaxRuns)}
+	if cfg.MaxDuration > 0 {
+		b.deadline = time.Now().Add(cfg.MaxDuration)
+	}
+	return b
+}
+
+// exhausted returns true if no more runs should be started.
+func (b *budget) exhausted() bool {
+	if b.maxRuns > 0 && atomic.LoadInt64(&b.runs) >= b.maxRuns {
+		return true
+	}
+	return !b.deadline.IsZero() && time.Now().After(b.deadline)
+}
+
+// use records that a run was executed.
+func (b *budget) use() {
+	atomic.AddInt64(&b.runs, 1)
+}
+
+// partition describes a part of the search tree.
+// The subtrees below the first splitDepth choice points are numbered in depth-first order (units),
+// and a partition consists of the units with unit % count == index.
+// Runs with fewer choice points form a unit of their own.
+type partition struct {
+	splitDepth int
+	index      int
+	count      int
+}
+
+// owns returns true if the current unit belongs to the partition explored by rs.
+func (rs *rState) owns() bool {
+	return rs.partition.count <= 1 || rs.unit%rs.partition.count == rs.partition.index
+}
+
+// skippedSubtree is used to abort a run that enters a subtree that belongs to another partition.
+type skippedSubtree struct{}
+
 func (rs *rState) exploreStates(runState func(s *state)) *state {
 	for !rs.done {
+		if rs.stop != nil && rs.stop(rs.unit) {
+			return nil
+		}
+		if rs.budget != nil && rs.budget.exhausted() {
+			rs.budgetExhausted = true
+			return nil
+		}
 		s := &state{
 			parent: rs,
 			log:    strings.Builder{},
@@ -33,6 +111,12 @@ func (rs *rState) exploreStates(runState func(s *state)) *state {
 					if _, ok := r.(emptyIterator); ok {
 						// ignore empty iterator error
 						s.failed = false
+						s.emptyIterator = true
+						return
+					}
+					if _, ok := r.(skippedSubtree); ok {
+						s.failed = false
+						s.skipped = true
 						return
 					}
 					// propagate other errors
@@ -46,11 +130,28 @@ func (rs *rState) exploreStates(runState func(s *state)) *state {
 		if rs.cfg.PrintAllLogs {
 			fmt.Printf("\n%s---\n", s.GetLog())
 		}
-		if s.failed {
-			// found a failed testcase
-			return s
+		if !s.skipped && rs.owns() {
+			if rs.budget != nil {
+				rs.budget.use()
+			}
+			if s.failed {
+				// found a failed testcase
+				return s
+			}
+			if s.discarded {
+				rs.discarded++
+			} else if s.emptyIterator {
+				rs.emptyIterators++
+			} else {
+				rs.passed++
+				rs.labelStats.Record(&s.labels)
+			}
 		}
 		rs.advanceStack(s.depth - 1)
+		if rs.continueAtDepth < rs.partition.splitDepth {
+			// the next run starts a new subtree
+			rs.unit++
+		}
 	}
 	return nil
 }
@@ -68,25 +169,49 @@ func (rs *rState) advanceStack(depth int) {
 		rs.continueAtDepth = depth
 		return
 	}
-	rs.runIsExhaustive = rs.runIsExhaustive && newCurrent.Exhaustive()
+	if !newCurrent.Exhaustive() {
+		rs.markNonExhaustive(entry.name)
+	}
 	// otherwise, we need to advance the stack one position below
 	rs.stack[depth] = nil
 	rs.advanceStack(depth - 1)
 }
 
+// markNonExhaustive records that the generator with the given name was not enumerated exhaustively.
+func (rs *rState) markNonExhaustive(name string) {
+	rs.runIsExhaustive = false
+	if rs.nonExhaustive == nil {
+		rs.nonExhaustive = make(map[string]bool)
+	}
+	rs.nonExhaustive[name] = true
+}
+
 type stackEntry struct {
+	// name of the generator
+	name     string
 	current  generator.UR
 	iterator geniterable.Iterator[generator.UR]
 }
 
 // state for a single iteration
 type state struct {
-	parent       *rState
-	log          strings.Builder
-	failed       bool
+	parent    *rState
+	log       strings.Builder
+	failed    bool
+	discarded bool
+	// set when the run was aborted because a generator had no values
+	emptyIterator bool
+	// set when the run was aborted because it entered a subtree of another partition
+	skipped      bool
 	depth        int
 	hasMoreCalls int
 	cleanup      []func()
+	// labels added to this test run
+	labels stats.Labels
+	// steps of this run so far, for showing outer values and generating a reproducer
+	steps []reproducer.Step
+	// functions marked with Helper
+	helpers callsite.Helpers
 }
 
 func (s *state) Cleanup(f func()) {
@@ -95,7 +220,7 @@ func (s *state) Cleanup(f func()) {
 
 func (s *state) Errorf(format string, args ...interface{}) {
 	s.failed = true
-	_, _ = fmt.Fprintf(&s.log, format, args...)
+	s.log.WriteString(s.helpers.Prefix() + callsite.TrimTrace(fmt.Sprintf(format, args...)))
 	s.log.WriteRune('\n')
 }
 
@@ -104,27 +229,67 @@ func (s *state) FailNow() {
 	panic(errTestFailed)
 }
 
+func (s *state) Assume(cond bool) {
+	if !cond {
+		s.Discard()
+	}
+}
+
+func (s *state) Discard() {
+	s.discarded = true
+	panic(errDiscarded)
+}
+
+func (s *state) Label(labels ...string) {
+	for _, l := range labels {
+		s.labels.Add(l)
+	}
+}
+
+func (s *state) Classify(cond bool, label string) {
+	if cond {
+		s.labels.Add(label)
+	}
+}
+
+func (s *state) Collect(value interface{}) {
+	s.labels.Add(fmt.Sprint(value))
+}
+
+func (s *state) Cover(minPercent float64, cond bool, label string) {
+	s.labels.Require(label, minPercent)
+	s.Classify(cond, label)
+}
+
 func (s *state) Failed() bool {
 	return s.failed
 }
 
 func (s *state) Logf(format string, args ...any) {
+	msg := s.helpers.Prefix() + fmt.Sprintf(format, args...)
 	if s.parent.cfg.PrintAllLogs {
-		fmt.Printf(format, args...)
-		fmt.Printf("\n")
+		fmt.Println(msg)
 		return
 	}
-	// TODO implement like in real Log and add source code line to message?
-	_, _ = fmt.Fprintf(&s.log, format, args...)
+	s.log.WriteString(msg)
 	s.log.WriteRune('\n')
 }
 
+// Helper marks the calling function as a test helper, like testing.T.Helper.
+// Log messages then show the location of the caller of the helper.
+func (s *state) Helper() {
+	s.helpers.Mark()
+}
+
 func (s *state) GetLog() string {
 	return s.log.String()
 }
 
 func (s *state) PickValue(gen generator.UntypedGenerator) generator.UV {
 	rs := s.parent
+	if s.depth == rs.partition.splitDepth && !rs.owns() {
+		panic(skippedSubtree{})
+	}
 	if s.depth < len(rs.stack) && s.depth <= rs.continueAtDepth {
 		// We already have an iterator.
 		// Return the current value and move to the next.
@@ -134,16 +299,20 @@ func (s *state) PickValue(gen generator.UntypedGenerator) generator.UV {
 		if !ok {
 			panic(emptyIterator{depth: s.depth})
 		}
+		s.steps = append(s.steps, reproducer.Value(value.Value))
 		return value
 	}
 	// We don't have an iterator for this stack level yet
 	it := gen.Enumerate(rs.maxDepth).Iterator()
 	current := it.Next()
 	if !current.Present() {
-		rs.runIsExhaustive = rs.runIsExhaustive && current.Exhaustive()
+		if !current.Exhaustive() {
+			rs.markNonExhaustive(gen.Name())
+		}
 		panic(emptyIterator{depth: s.depth})
 	}
 	newEntry := &stackEntry{
+		name:     gen.Name(),
 		iterator: it,
 		current:  current.Value(),
 	}
@@ -158,6 +327,7 @@ func (s *state) PickValue(gen generator.UntypedGenerator) generator.UV {
 	if !ok {
 		panic(emptyIterator{depth: s.depth})
 	}
+	s.steps = append(s.steps, reproducer.Value(value.Value))
 	return value
 }
 
@@ -165,9 +335,17 @@ type emptyIterator struct {
 	depth int
 }
 
+// HasMore returns false once the loop has run maxDepth-1 times.
+// The cutoff makes the run non-exhaustive, so that longer loops are explored with a larger depth.
 func (s *state) HasMore() bool {
 	s.hasMoreCalls++
-	return s.hasMoreCalls < s.parent.maxDepth
+	if s.hasMoreCalls >= s.parent.maxDepth {
+		s.parent.markNonExhaustive("HasMore")
+		s.steps = append(s.steps, reproducer.HasMore(false))
+		return false
+	}
+	s.steps = append(s.steps, reproducer.HasMore(true))
+	return true
 }
 
 func (s *state) runCleanups() {
@@ -32,3 +32,68 @@ func TestExplore(t *testing.T) {
 	require.Contains(t, explored, "[1, 1, 1]")
 	require.Contains(t, explored, "[-1, -1, -1]")
 }
+
+func TestExplorePartitions(t *testing.T) {
+	explore := func(p partition) map[string]bool {
+		rs := rState{
+			maxDepth:        3,
+			runIsExhaustive: true,
+			partition:       p,
+		}
+		explored := make(map[string]bool)
+		rs.exploreStates(func(s *state) {
+			x := pick.Val(s, generator.Int())
+			y := pick.Val(s, generator.Int())
+			z := pick.Val(s, generator.Int())
+			explored[fmt.Sprintf("[%d, %d, %d]", x, y, z)] = true
+		})
+		return explored
+	}
+
+	all := explore(partition{})
+	union := make(map[string]bool)
+	for i := 0; i < 3; i++ {
+		part := explore(partition{splitDepth: 2, index: i, count: 3})
+		require.NotEmpty(t, part)
+		for k := range part {
+			require.False(t, union[k], "%s explored twice", k)
+			union[k] = true
+		}
+	}
+	require.Equal(t, all, union)
+}
+
+func TestExplorePartitionsOverhead(t *testing.T) {
+	// explore returns the number of calls of the test function and the number of units
+	explore := func(p partition) (calls int, units int) {
+		rs := rState{
+			maxDepth:        3,
+			runIsExhaustive: true,
+			partition:       p,
+		}
+		rs.exploreStates(func(s *state) {
+			calls++
+			x := pick.Val(s, generator.IntRange(0, 3))
+			if x == 0 {
+				// a run with fewer choice points than the split depth
+				return
+			}
+			pick.Val(s, generator.IntRange(0, 3))
+			pick.Val(s, generator.IntRange(0, 3))
+		})
+		// units are numbered from 0
+		return calls, rs.unit + 1
+	}
+
+	sequential, units := explore(partition{splitDepth: 2, count: 1})
+	total := 0
+	const count = 4
+	for i := 0; i < count; i++ {
+		calls, _ := explore(partition{splitDepth: 2, index: i, count: count})
+		total += calls
+	}
+	// every partition runs the test function once for each unit of the other partitions
+	t.Logf("seq=%d units=%d total=%d", sequential, units, total)
+	require.LessOrEqual(t, total, sequential+(count-1)*units)
+	require.Greater(t, total, sequential)
+}
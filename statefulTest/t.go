@@ -9,10 +9,28 @@ type T interface {
 	Errorf(format string, args ...interface{})
 	FailNow()
 	Logf(format string, args ...any)
+	// Helper marks the calling function as a test helper, like testing.T.Helper.
+	// Log messages and errors then show the location of the caller of the helper.
+	Helper()
 	// PickValue returns the next value
 	PickValue(untyped generator.UntypedGenerator) generator.UV
 	// HasMore is used for generating a sequence of values
 	HasMore() bool
 	// Cleanup runs a function when the test is done
 	Cleanup(f func())
+	// Assume discards the current test run if cond is false.
+	// Use it to reject inputs that are not valid for the property.
+	Assume(cond bool)
+	// Discard aborts the current test run without failing it.
+	// Discarded runs are counted separately and do not count as successful runs.
+	Discard()
+	// Label adds labels to the current test run.
+	// At the end, the percentage of test runs with each label is reported.
+	Label(labels ...string)
+	// Classify adds the label to the current test run if cond is true.
+	Classify(cond bool, label string)
+	// Collect adds the formatted value as a label to the current test run.
+	Collect(value interface{})
+	// Cover works like Classify, but additionally fails the test if fewer than minPercent percent of the test runs have the label.
+	Cover(minPercent float64, cond bool, label string)
 }
@@ -0,0 +1,224 @@
+package statemachine
+
+import (
+	"fmt"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/peterzeller/go-stateful-test/generator"
+	"github.com/peterzeller/go-stateful-test/internal/callsite"
+	"github.com/peterzeller/go-stateful-test/statefulTest"
+)
+
+// operation is a command that was executed in a parallel suffix
+type operation[M, S any] struct {
+	step[M, S]
+	// result of the command
+	result interface{}
+	// logical timestamps of the invocation and the response
+	invoked, returned int64
+}
+
+// history records the operations executed by each thread
+type history[M, S any] [][]operation[M, S]
+
+func (h history[M, S]) String() string {
+	var res strings.Builder
+	for i, ops := range h {
+		_, _ = fmt.Fprintf(&res, "Parallel %d:\n", i)
+		for _, op := range ops {
+			_, _ = fmt.Fprintf(&res, "  [%d-%d] %s -> %v\n", op.invoked, op.returned, op.step, op.result)
+		}
+	}
+	return res.String()
+}
+
+// runParallel executes the suffixes on separate goroutines and records the history.
+// Failures in the commands are reported to t after all goroutines have finished.
+func runParallel[M, S any](t statefulTest.T, sut S, suffixes [][]step[M, S]) history[M, S] {
+	var clock int64
+	h := make(history[M, S], len(suffixes))
+	threads := make([]*threadT, len(suffixes))
+	var wg sync.WaitGroup
+	// start signal, so that the goroutines start at roughly the same time
+	start := make(chan struct{})
+	for i, suffix := range suffixes {
+		i, suffix := i, suffix
+		threads[i] = &threadT{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			h[i] = runThread(threads[i], sut, suffix, &clock)
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	for i, th := range threads {
+		for _, f := range th.cleanup {
+			t.Cleanup(f)
+		}
+		if th.failed {
+			t.Errorf("Parallel %d failed:\n%s\nHistory:\n%s", i, th.log.String(), h)
+			t.FailNow()
+		}
+	}
+	return h
+}
+
+func runThread[M, S any](t *threadT, sut S, suffix []step[M, S], clock *int64) (ops []operation[M, S]) {
+	defer func() {
+		if r := recover(); r != nil {
+			if r != errThreadFailed {
+				t.Errorf("Panic in command:\n%v\n%s", r, debug.Stack())
+			}
+		}
+	}()
+	for _, s := range suffix {
+		op := operation[M, S]{step: s}
+		op.invoked = atomic.AddInt64(clock, 1)
+		op.result = s.cmd.run(t, sut, s.arg)
+		op.returned = atomic.AddInt64(clock, 1)
+		ops = append(ops, op)
+	}
+	return ops
+}
+
+// isLinearizable checks whether there is a sequential order of the operations in the history that
+// respects the real-time order of the operations and is accepted by the model.
+// The order is accepted if all preconditions, postconditions and the invariant hold when executing it on the model.
+// The invariant is checked after every operation, like in a sequential test.
+func isLinearizable[M, S any](spec Spec[M, S], sut S, model M, h history[M, S]) bool {
+	next := make([]int, len(h))
+	invariant := func(model M) bool {
+		return spec.Invariant == nil || holds(func(t statefulTest.T) {
+			spec.Invariant(t, model, sut)
+		})
+	}
+	var search func(model M) bool
+	search = func(model M) bool {
+		// an operation can only be linearized next if it was invoked before all other pending operations returned
+		minReturned := int64(-1)
+		done := true
+		for thread, ops := range h {
+			if next[thread] < len(ops) {
+				done = false
+				if r := ops[next[thread]].returned; minReturned < 0 || r < minReturned {
+					minReturned = r
+				}
+			}
+		}
+		if done {
+			return true
+		}
+		for thread, ops := range h {
+			if next[thread] >= len(ops) {
+				continue
+			}
+			op := ops[next[thread]]
+			if op.invoked > minReturned || !op.cmd.precondition(model) {
+				continue
+			}
+			if !holds(func(t statefulTest.T) {
+				op.cmd.postcondition(t, model, op.arg, op.result)
+			}) {
+				continue
+			}
+			nextModel := op.cmd.nextState(model, op.arg)
+			if !invariant(nextModel) {
+				continue
+			}
+			next[thread]++
+			found := search(nextModel)
+			next[thread]--
+			if found {
+				return true
+			}
+		}
+		return false
+	}
+	return search(model)
+}
+
+// holds checks whether the assertions in f hold
+func holds(f func(t statefulTest.T)) (result bool) {
+	t := &threadT{}
+	defer func() {
+		if r := recover(); r != nil {
+			if r != errThreadFailed {
+				panic(r)
+			}
+			result = false
+		}
+	}()
+	f(t)
+	return !t.failed
+}
+
+var errThreadFailed = fmt.Errorf("command failed")
+
+// threadT is the statefulTest.T used for commands in parallel suffixes and for checking conditions.
+// It collects logs and errors, which are reported after all goroutines have finished.
+type threadT struct {
+	log     strings.Builder
+	failed  bool
+	cleanup []func()
+	helpers callsite.Helpers
+}
+
+var _ statefulTest.T = &threadT{}
+
+func (t *threadT) Errorf(format string, args ...interface{}) {
+	t.failed = true
+	t.log.WriteString(t.helpers.Prefix() + callsite.TrimTrace(fmt.Sprintf(format, args...)))
+	t.log.WriteRune('\n')
+}
+
+func (t *threadT) FailNow() {
+	t.failed = true
+	panic(errThreadFailed)
+}
+
+func (t *threadT) Logf(format string, args ...any) {
+	t.log.WriteString(t.helpers.Prefix() + fmt.Sprintf(format, args...))
+	t.log.WriteRune('\n')
+}
+
+func (t *threadT) Helper() {
+	t.helpers.Mark()
+}
+
+func (t *threadT) PickValue(untyped generator.UntypedGenerator) generator.UV {
+	panic(fmt.Errorf("PickValue cannot be used in parallel commands"))
+}
+
+func (t *threadT) HasMore() bool {
+	panic(fmt.Errorf("HasMore cannot be used in parallel commands"))
+}
+
+func (t *threadT) Assume(cond bool) {
+	if !cond {
+		t.Discard()
+	}
+}
+
+func (t *threadT) Discard() {
+	panic(fmt.Errorf("Discard cannot be used in parallel commands"))
+}
+
+// Labels in parallel commands and conditions are ignored,
+// because conditions are evaluated many times while searching for a linearization.
+func (t *threadT) Label(labels ...string) {}
+
+func (t *threadT) Classify(cond bool, label string) {}
+
+func (t *threadT) Collect(value interface{}) {}
+
+func (t *threadT) Cover(minPercent float64, cond bool, label string) {}
+
+func (t *threadT) Cleanup(f func()) {
+	t.cleanup = append(t.cleanup, f)
+}
@@ -0,0 +1,318 @@
+package statemachine
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/peterzeller/go-fun/iterable"
+	"github.com/peterzeller/go-fun/list/linked"
+	"github.com/peterzeller/go-stateful-test/generator"
+	"github.com/peterzeller/go-stateful-test/generator/geniterable"
+	"github.com/peterzeller/go-stateful-test/generator/shrink"
+	"github.com/peterzeller/go-stateful-test/pick"
+	"github.com/peterzeller/go-stateful-test/quickcheck"
+	"github.com/peterzeller/go-stateful-test/statefulTest"
+)
+
+// ParallelConfig configures parallel state machine tests.
+type ParallelConfig struct {
+	// Threads is the number of command sequences that are executed in parallel (default: 2)
+	Threads int
+	// MaxPrefixLength is the maximum length of the sequential prefix (default: 5)
+	MaxPrefixLength int
+	// MaxParallelLength is the maximum length of each parallel command sequence (default: 4).
+	// The number of interleavings grows exponentially with this length, so it should be kept small.
+	MaxParallelLength int
+	// Repetitions is the number of times each test case is executed,
+	// which increases the chance of observing a problematic interleaving (default: 10)
+	Repetitions int
+}
+
+func (cfg ParallelConfig) withDefaults() ParallelConfig {
+	if cfg.Threads == 0 {
+		cfg.Threads = 2
+	}
+	if cfg.MaxPrefixLength == 0 {
+		cfg.MaxPrefixLength = 5
+	}
+	if cfg.MaxParallelLength == 0 {
+		cfg.MaxParallelLength = 4
+	}
+	if cfg.Repetitions == 0 {
+		cfg.Repetitions = 10
+	}
+	return cfg
+}
+
+// ParallelProperty returns a test function that checks the system under test for linearizability.
+//
+// Each test case consists of a sequential prefix and several command sequences that are executed in parallel
+// on separate goroutines after the prefix.
+// The test fails if there is no sequential ordering of the parallel commands that is consistent with
+// the observed history and satisfies all postconditions and the invariant when executed on the model.
+// Preconditions must hold in every possible interleaving of the parallel commands.
+//
+// The Run functions of the commands are executed concurrently and must not use t.PickValue or t.HasMore.
+func (spec Spec[M, S]) ParallelProperty(cfg ParallelConfig) func(t statefulTest.T) {
+	cfg = cfg.withDefaults()
+	var gen generator.Generator[parallelProgram[M, S], parallelR] = parallelGen[M, S]{seq: sequenceGen[M, S]{spec: spec}, cfg: cfg}
+	return func(t statefulTest.T) {
+		prog := pick.Val(t, gen)
+		t.Logf("%s", prog)
+		for rep := 0; rep < cfg.Repetitions; rep++ {
+			sut := spec.NewSUT(t)
+			model := spec.InitialModel()
+			for _, s := range prog.prefix {
+				res := s.cmd.run(t, sut, s.arg)
+				s.cmd.postcondition(t, model, s.arg, res)
+				model = s.cmd.nextState(model, s.arg)
+				if spec.Invariant != nil {
+					spec.Invariant(t, model, sut)
+				}
+			}
+			h := runParallel(t, sut, prog.suffixes)
+			if !isLinearizable(spec, sut, model, h) {
+				t.Errorf("History is not linearizable (repetition %d):\n%s", rep, h)
+				t.FailNow()
+			}
+		}
+	}
+}
+
+// RunParallel checks the system under test for linearizability using quickcheck.
+// See Spec.ParallelProperty for details.
+func RunParallel[M, S any](t quickcheck.TestingT, cfg quickcheck.Config, pcfg ParallelConfig, spec Spec[M, S]) {
+	quickcheck.Run(t, cfg, spec.ParallelProperty(pcfg))
+}
+
+// parallelProgram is a sequential prefix followed by command sequences that are executed in parallel
+type parallelProgram[M, S any] struct {
+	prefix   []step[M, S]
+	suffixes [][]step[M, S]
+}
+
+func (p parallelProgram[M, S]) String() string {
+	var res strings.Builder
+	res.WriteString("Sequential prefix:\n")
+	res.WriteString(formatSteps(p.prefix))
+	for i, suffix := range p.suffixes {
+		_, _ = fmt.Fprintf(&res, "Parallel %d:\n", i)
+		res.WriteString(formatSteps(suffix))
+	}
+	return res.String()
+}
+
+// parallelR is the internal representation of a parallelProgram
+type parallelR struct {
+	prefix   []stepR
+	suffixes [][]stepR
+}
+
+// parallelGen generates parallel programs where the preconditions hold in every interleaving.
+type parallelGen[M, S any] struct {
+	seq sequenceGen[M, S]
+	cfg ParallelConfig
+}
+
+var _ generator.Generator[parallelProgram[int, int], parallelR] = parallelGen[int, int]{}
+
+func (g parallelGen[M, S]) Name() string {
+	return "statemachine.parallelProgram"
+}
+
+// from returns a sequence generator that starts in the given model state
+func (g sequenceGen[M, S]) from(model M) sequenceGen[M, S] {
+	spec := g.spec
+	spec.InitialModel = func() M {
+		return model
+	}
+	return sequenceGen[M, S]{spec: spec}
+}
+
+func (g parallelGen[M, S]) Random(rnd generator.Rand, size int) parallelR {
+	prefix := g.seq.Random(rnd, atMost(size, g.cfg.MaxPrefixLength))
+	model := g.seq.simulateFrom(g.seq.spec.InitialModel(), prefix, ignoreStep[M, S])
+	suffixes := make([][]stepR, g.cfg.Threads)
+	for i := range suffixes {
+		suffixes[i] = g.seq.from(model).Random(rnd, atMost(size, g.cfg.MaxParallelLength))
+	}
+	return g.normalize(parallelR{prefix: prefix, suffixes: suffixes})
+}
+
+func ignoreStep[M, S any](i int, model M, cmd Command[M, S], argGen generator.UntypedGenerator, arg generator.UV) {
+}
+
+// atMost limits n to the given limit
+func atMost(n, limit int) int {
+	if n > limit {
+		return limit
+	}
+	return n
+}
+
+// parallelStep is a valid step in a parallel suffix
+type parallelStep[M, S any] struct {
+	step[M, S]
+	// index of the step in the suffix
+	index int
+}
+
+// validSuffixes returns the valid steps of every suffix, starting in the given model state
+func (g parallelGen[M, S]) validSuffixes(model M, suffixes [][]stepR) [][]parallelStep[M, S] {
+	res := make([][]parallelStep[M, S], len(suffixes))
+	for i, suffix := range suffixes {
+		g.seq.simulateFrom(model, suffix, func(j int, model M, cmd Command[M, S], argGen generator.UntypedGenerator, arg generator.UV) {
+			res[i] = append(res[i], parallelStep[M, S]{step: step[M, S]{cmd: cmd, arg: arg}, index: j})
+		})
+	}
+	return res
+}
+
+// normalize removes invalid steps from the prefix and the suffixes.
+// A step in a suffix is only valid if its precondition holds in every interleaving of the suffixes.
+func (g parallelGen[M, S]) normalize(r parallelR) parallelR {
+	prefix := g.seq.normalize(r.prefix)
+	model := g.seq.simulateFrom(g.seq.spec.InitialModel(), prefix, ignoreStep[M, S])
+	suffixes := make([][]stepR, len(r.suffixes))
+	copy(suffixes, r.suffixes)
+	for {
+		for i, suffix := range suffixes {
+			suffixes[i] = g.seq.from(model).normalize(suffix)
+		}
+		steps := g.validSuffixes(model, suffixes)
+		thread, index, ok := findPreconditionViolation(model, steps, make([]int, len(steps)))
+		if ok {
+			return parallelR{prefix: prefix, suffixes: suffixes}
+		}
+		// remove the violating step and try again
+		pos := steps[thread][index].index
+		suffixes[thread] = append(append([]stepR{}, suffixes[thread][:pos]...), suffixes[thread][pos+1:]...)
+	}
+}
+
+// findPreconditionViolation checks that the preconditions hold in all interleavings of the steps.
+// If there is an interleaving where a precondition does not hold, it returns the thread and index of the step.
+func findPreconditionViolation[M, S any](model M, steps [][]parallelStep[M, S], next []int) (int, int, bool) {
+	for thread, ss := range steps {
+		if next[thread] >= len(ss) {
+			continue
+		}
+		s := ss[next[thread]]
+		if !s.cmd.precondition(model) {
+			return thread, next[thread], false
+		}
+		next[thread]++
+		t, i, ok := findPreconditionViolation(s.cmd.nextState(model, s.arg), steps, next)
+		next[thread]--
+		if !ok {
+			return t, i, false
+		}
+	}
+	return 0, 0, true
+}
+
+func (g parallelGen[M, S]) RValue(r parallelR) (parallelProgram[M, S], bool) {
+	r = g.normalize(r)
+	prefix, _ := g.seq.RValue(r.prefix)
+	model := g.seq.simulateFrom(g.seq.spec.InitialModel(), r.prefix, ignoreStep[M, S])
+	suffixes := make([][]step[M, S], len(r.suffixes))
+	for i, suffix := range r.suffixes {
+		suffixes[i], _ = g.seq.from(model).RValue(suffix)
+	}
+	return parallelProgram[M, S]{prefix: prefix, suffixes: suffixes}, true
+}
+
+func (g parallelGen[M, S]) Shrink(r parallelR) iterable.Iterable[parallelR] {
+	r = g.normalize(r)
+	model := g.seq.simulateFrom(g.seq.spec.InitialModel(), r.prefix, ignoreStep[M, S])
+	withSuffix := func(i int, suffix []stepR) parallelR {
+		suffixes := make([][]stepR, len(r.suffixes))
+		copy(suffixes, r.suffixes)
+		suffixes[i] = suffix
+		return parallelR{prefix: r.prefix, suffixes: suffixes}
+	}
+	// remove commands from the parallel suffixes
+	suffixRemovals := iterable.FlatMap(iterable.Range(0, len(r.suffixes)),
+		func(i int) iterable.Iterable[parallelR] {
+			removals := shrink.ShrinkList(linked.New(r.suffixes[i]...), func(s stepR) iterable.Iterable[stepR] {
+				return iterable.Empty[stepR]()
+			})
+			return iterable.Map(removals, func(l *linked.List[stepR]) parallelR {
+				return withSuffix(i, l.ToSlice())
+			})
+		})
+	// move the first command of a suffix to the sequential prefix
+	moveToPrefix := iterable.FlatMap(iterable.Range(0, len(r.suffixes)),
+		func(i int) iterable.Iterable[parallelR] {
+			if len(r.suffixes[i]) == 0 {
+				return iterable.Empty[parallelR]()
+			}
+			res := withSuffix(i, r.suffixes[i][1:])
+			res.prefix = append(append([]stepR{}, r.prefix...), r.suffixes[i][0])
+			return iterable.Singleton(res)
+		})
+	// shrink the prefix
+	prefixShrinks := iterable.Map(g.seq.Shrink(r.prefix), func(prefix []stepR) parallelR {
+		return parallelR{prefix: prefix, suffixes: r.suffixes}
+	})
+	// shrink the arguments in the suffixes
+	suffixShrinks := iterable.FlatMap(iterable.Range(0, len(r.suffixes)),
+		func(i int) iterable.Iterable[parallelR] {
+			return iterable.Map(g.seq.from(model).Shrink(r.suffixes[i]), func(suffix []stepR) parallelR {
+				return withSuffix(i, suffix)
+			})
+		})
+	return iterable.Map(
+		iterable.Concat(suffixRemovals, moveToPrefix, prefixShrinks, suffixShrinks),
+		g.normalize)
+}
+
+// Size counts commands in the parallel suffixes twice, so that moving commands to the prefix is a shrink.
+func (g parallelGen[M, S]) Size(r parallelR) *big.Int {
+	r = g.normalize(r)
+	size := g.seq.Size(r.prefix)
+	model := g.seq.simulateFrom(g.seq.spec.InitialModel(), r.prefix, ignoreStep[M, S])
+	for _, suffix := range r.suffixes {
+		suffixSize := g.seq.from(model).Size(suffix)
+		size.Add(size, suffixSize)
+		size.Add(size, suffixSize)
+	}
+	return size
+}
+
+// Enumerate all parallel programs with prefix and suffixes of up to length depth.
+func (g parallelGen[M, S]) Enumerate(depth int) geniterable.Iterable[parallelR] {
+	return geniterable.FlatMap(g.seq.Enumerate(atMost(depth, g.cfg.MaxPrefixLength)),
+		func(prefix []stepR) geniterable.Iterable[parallelR] {
+			model := g.seq.simulateFrom(g.seq.spec.InitialModel(), prefix, ignoreStep[M, S])
+			suffixes := g.seq.from(model).Enumerate(atMost(depth, g.cfg.MaxParallelLength))
+			return geniterable.Filter(
+				geniterable.Map(enumerateProduct(g.cfg.Threads, suffixes),
+					func(suffixes [][]stepR) parallelR {
+						return parallelR{prefix: prefix, suffixes: suffixes}
+					}),
+				func(r parallelR) bool {
+					// only keep programs that are valid in every interleaving
+					n := g.normalize(r)
+					for i := range n.suffixes {
+						if len(n.suffixes[i]) != len(r.suffixes[i]) {
+							return false
+						}
+					}
+					return true
+				})
+		})
+}
+
+// enumerateProduct enumerates all combinations of n elements from the given iterable
+func enumerateProduct[T any](n int, elems geniterable.Iterable[T]) geniterable.Iterable[[]T] {
+	if n <= 0 {
+		return geniterable.Singleton([]T{})
+	}
+	return geniterable.FlatMap(elems, func(first T) geniterable.Iterable[[]T] {
+		return geniterable.Map(enumerateProduct(n-1, elems), func(rest []T) []T {
+			return append([]T{first}, rest...)
+		})
+	})
+}
@@ -0,0 +1,188 @@
+package statemachine
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/peterzeller/go-fun/iterable"
+	"github.com/peterzeller/go-fun/list/linked"
+	"github.com/peterzeller/go-stateful-test/generator"
+	"github.com/peterzeller/go-stateful-test/generator/geniterable"
+	"github.com/peterzeller/go-stateful-test/generator/shrink"
+)
+
+// stepR is the internal representation of a step: the index of the command and the representation of its argument
+type stepR struct {
+	command int
+	arg     generator.UR
+}
+
+// sequenceGen generates sequences of commands that satisfy the preconditions of the commands.
+type sequenceGen[M, S any] struct {
+	spec Spec[M, S]
+}
+
+var _ generator.Generator[[]step[int, int], []stepR] = sequenceGen[int, int]{}
+
+func commandSequence[M, S any](spec Spec[M, S]) generator.Generator[[]step[M, S], []stepR] {
+	return sequenceGen[M, S]{spec: spec}
+}
+
+func (g sequenceGen[M, S]) Name() string {
+	return "statemachine.commandSequence"
+}
+
+func (g sequenceGen[M, S]) Random(rnd generator.Rand, size int) []stepR {
+	length := generator.Choose(rnd, size+1)
+	res := make([]stepR, 0, length)
+	model := g.spec.InitialModel()
+	for n := 0; n < length; n++ {
+		enabled := g.enabledCommands(model)
+		if len(enabled) == 0 {
+			break
+		}
+		i := enabled[generator.Choose(rnd, len(enabled))]
+		cmd := g.spec.Commands[i]
+		argGen := cmd.argGenerator(model)
+		argR := argGen.Random(rnd, size)
+		arg, ok := argGen.RValue(argR)
+		if !ok {
+			continue
+		}
+		res = append(res, stepR{command: i, arg: argR})
+		model = cmd.nextState(model, arg)
+	}
+	return res
+}
+
+func (g sequenceGen[M, S]) enabledCommands(model M) []int {
+	var res []int
+	for i, cmd := range g.spec.Commands {
+		if cmd.precondition(model) {
+			res = append(res, i)
+		}
+	}
+	return res
+}
+
+// simulate replays the steps on the model.
+// Steps that are not valid (precondition does not hold or argument is invalid) are skipped.
+// The function visit is called for every valid step with the model state before the step.
+func (g sequenceGen[M, S]) simulate(rs []stepR, visit func(i int, model M, cmd Command[M, S], argGen generator.UntypedGenerator, arg generator.UV)) {
+	g.simulateFrom(g.spec.InitialModel(), rs, visit)
+}
+
+// simulateFrom works like simulate, but starts in the given model state.
+// Returns the model state after the valid steps.
+func (g sequenceGen[M, S]) simulateFrom(model M, rs []stepR, visit func(i int, model M, cmd Command[M, S], argGen generator.UntypedGenerator, arg generator.UV)) M {
+	for i, r := range rs {
+		if r.command < 0 || r.command >= len(g.spec.Commands) {
+			continue
+		}
+		cmd := g.spec.Commands[r.command]
+		if !cmd.precondition(model) {
+			continue
+		}
+		argGen := cmd.argGenerator(model)
+		arg, ok := argGen.RValue(r.arg)
+		if !ok {
+			continue
+		}
+		visit(i, model, cmd, argGen, arg)
+		model = cmd.nextState(model, arg)
+	}
+	return model
+}
+
+func (g sequenceGen[M, S]) RValue(rs []stepR) ([]step[M, S], bool) {
+	res := make([]step[M, S], 0, len(rs))
+	g.simulate(rs, func(i int, model M, cmd Command[M, S], argGen generator.UntypedGenerator, arg generator.UV) {
+		res = append(res, step[M, S]{cmd: cmd, arg: arg})
+	})
+	return res, true
+}
+
+// normalize removes all steps that are not valid
+func (g sequenceGen[M, S]) normalize(rs []stepR) []stepR {
+	res := make([]stepR, 0, len(rs))
+	g.simulate(rs, func(i int, model M, cmd Command[M, S], argGen generator.UntypedGenerator, arg generator.UV) {
+		res = append(res, rs[i])
+	})
+	return res
+}
+
+func (g sequenceGen[M, S]) Shrink(rs []stepR) iterable.Iterable[[]stepR] {
+	rs = g.normalize(rs)
+	// first try to remove commands
+	removals := shrink.ShrinkList(linked.New(rs...), func(r stepR) iterable.Iterable[stepR] {
+		return iterable.Empty[stepR]()
+	})
+	// then try to shrink the arguments of single commands
+	argShrinks := iterable.FlatMap(iterable.Range(0, len(rs)),
+		func(i int) iterable.Iterable[[]stepR] {
+			var shrinks iterable.Iterable[generator.UR] = iterable.Empty[generator.UR]()
+			g.simulate(rs[:i+1], func(j int, model M, cmd Command[M, S], argGen generator.UntypedGenerator, arg generator.UV) {
+				if j == i {
+					shrinks = argGen.Shrink(rs[i].arg)
+				}
+			})
+			return iterable.Map(shrinks, func(arg generator.UR) []stepR {
+				res := make([]stepR, len(rs))
+				copy(res, rs)
+				res[i] = stepR{command: rs[i].command, arg: arg}
+				return res
+			})
+		})
+	return iterable.Map(
+		iterable.Concat(
+			iterable.Map(removals, func(l *linked.List[stepR]) []stepR {
+				return l.ToSlice()
+			}),
+			argShrinks),
+		g.normalize)
+}
+
+func (g sequenceGen[M, S]) Size(rs []stepR) *big.Int {
+	var size big.Int
+	g.simulate(rs, func(i int, model M, cmd Command[M, S], argGen generator.UntypedGenerator, arg generator.UV) {
+		size.Add(&size, big.NewInt(1))
+		size.Add(&size, argGen.Size(rs[i].arg))
+	})
+	return &size
+}
+
+// Enumerate all command sequences up to length depth, shorter sequences first.
+func (g sequenceGen[M, S]) Enumerate(depth int) geniterable.Iterable[[]stepR] {
+	return geniterable.NonExhaustive(
+		geniterable.FlatMap(geniterable.RangeI(0, depth),
+			func(length int) geniterable.Iterable[[]stepR] {
+				return g.enumerateLength(g.spec.InitialModel(), length, depth)
+			}))
+}
+
+// enumerateLength enumerates all command sequences of exactly the given length starting in the given model state.
+func (g sequenceGen[M, S]) enumerateLength(model M, length int, depth int) geniterable.Iterable[[]stepR] {
+	if length <= 0 {
+		return geniterable.Singleton([]stepR{})
+	}
+	return geniterable.FlatMap(geniterable.FromSlice(g.enabledCommands(model)),
+		func(i int) geniterable.Iterable[[]stepR] {
+			cmd := g.spec.Commands[i]
+			argGen := cmd.argGenerator(model)
+			return geniterable.FlatMap(argGen.Enumerate(depth),
+				func(argR generator.UR) geniterable.Iterable[[]stepR] {
+					arg, ok := argGen.RValue(argR)
+					if !ok {
+						return geniterable.Empty[[]stepR]()
+					}
+					return geniterable.Map(g.enumerateLength(cmd.nextState(model, arg), length-1, depth),
+						func(tail []stepR) []stepR {
+							return append([]stepR{{command: i, arg: argR}}, tail...)
+						})
+				})
+		})
+}
+
+func (s stepR) String() string {
+	return fmt.Sprintf("step(%d, %v)", s.command, s.arg)
+}
@@ -0,0 +1,155 @@
+package statemachine
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/peterzeller/go-fun/iterable"
+	"github.com/peterzeller/go-stateful-test/generator"
+	"github.com/peterzeller/go-stateful-test/generator/geniterable"
+	"github.com/peterzeller/go-stateful-test/quickcheck"
+	"github.com/peterzeller/go-stateful-test/statefulTest"
+	"github.com/stretchr/testify/require"
+)
+
+type testRand struct {
+	rnd *rand.Rand
+}
+
+func (r testRand) Fork(name string) generator.Rand {
+	return r
+}
+
+func (r testRand) HasMore() bool {
+	return false
+}
+
+func (r testRand) R() *rand.Rand {
+	return r.rnd
+}
+
+// counterSpec has a counter model, where dec is only allowed if the counter is positive
+func counterSpec() Spec[int, *int] {
+	return Spec[int, *int]{
+		InitialModel: func() int { return 0 },
+		NewSUT: func(t statefulTest.T) *int {
+			return new(int)
+		},
+		Commands: []Command[int, *int]{
+			Cmd[int, *int, int, struct{}]{
+				Name: "add",
+				Args: func(m int) generator.Generator[int, interface{}] {
+					return generator.UntypedR(generator.IntRange(1, 5))
+				},
+				Run: func(t statefulTest.T, sut *int, arg int) struct{} {
+					*sut += arg
+					return struct{}{}
+				},
+				Next: func(m int, arg int) int { return m + arg },
+			},
+			Cmd[int, *int, struct{}, struct{}]{
+				Name: "dec",
+				Pre:  func(m int) bool { return m > 0 },
+				Run: func(t statefulTest.T, sut *int, _ struct{}) struct{} {
+					*sut--
+					return struct{}{}
+				},
+				Next: func(m int, _ struct{}) int { return m - 1 },
+			},
+		},
+	}
+}
+
+// requireValid checks that the preconditions hold for all steps
+func requireValid(t *testing.T, steps []step[int, *int]) {
+	model := 0
+	for _, s := range steps {
+		require.True(t, s.cmd.precondition(model), "precondition of %s in %v", s, steps)
+		model = s.cmd.nextState(model, s.arg)
+	}
+}
+
+func TestShrinkKeepsPreconditions(t *testing.T) {
+	g := commandSequence(counterSpec())
+	rnd := testRand{rnd: rand.New(rand.NewSource(1))}
+	for i := 0; i < 20; i++ {
+		rs := g.Random(rnd, 20)
+		for it := iterable.Start(g.Shrink(rs)); it.HasNext(); it.Next() {
+			steps, ok := g.RValue(it.Current())
+			require.True(t, ok)
+			requireValid(t, steps)
+			require.Equal(t, len(steps), len(it.Current()), "shrinks are normalized")
+			require.True(t, g.Size(it.Current()).Cmp(g.Size(rs)) < 0, "shrinks are smaller")
+		}
+	}
+}
+
+func TestEnumerateSequences(t *testing.T) {
+	g := commandSequence(counterSpec())
+	var calls []string
+	for it := geniterable.Start(generator.EnumerateValues(g, 2)); it.HasNext(); it.Next() {
+		requireValid(t, it.Current())
+		calls = append(calls, formatSteps(it.Current()))
+	}
+	require.Equal(t, []string{
+		"",
+		"  0: add(1)\n",
+		"  0: add(2)\n",
+		"  0: add(1)\n  1: add(1)\n",
+		"  0: add(1)\n  1: add(2)\n",
+		"  0: add(1)\n  1: dec()\n",
+		"  0: add(2)\n  1: add(1)\n",
+		"  0: add(2)\n  1: add(2)\n",
+		"  0: add(2)\n  1: dec()\n",
+	}, calls)
+}
+
+func TestIsLinearizable(t *testing.T) {
+	spec := counterSpec()
+	add := spec.Commands[0]
+	op := func(arg int, invoked, returned int64) operation[int, *int] {
+		return operation[int, *int]{
+			step:     step[int, *int]{cmd: add, arg: generator.UV{Value: arg}},
+			result:   struct{}{},
+			invoked:  invoked,
+			returned: returned,
+		}
+	}
+	sut := new(int)
+	// overlapping operations can be ordered arbitrarily
+	require.True(t, isLinearizable(spec, sut, 0, history[int, *int]{{op(1, 1, 3)}, {op(2, 2, 4)}}))
+	// the invariant is checked after every operation, so add(2) must come first
+	spec.Invariant = func(t statefulTest.T, m int, sut *int) {
+		require.NotEqual(t, 1, m)
+	}
+	require.True(t, isLinearizable(spec, sut, 0, history[int, *int]{{op(1, 1, 3)}, {op(2, 2, 4)}}))
+	// add(1) returned before add(2) was invoked, so the intermediate state violates the invariant
+	require.False(t, isLinearizable(spec, sut, 0, history[int, *int]{{op(1, 1, 2)}, {op(2, 3, 4)}}))
+}
+
+func TestNilResult(t *testing.T) {
+	// commands with an interface result type may return nil
+	spec := Spec[int, *int]{
+		InitialModel: func() int { return 0 },
+		NewSUT: func(t statefulTest.T) *int {
+			return new(int)
+		},
+		Commands: []Command[int, *int]{
+			Cmd[int, *int, struct{}, error]{
+				Name: "inc",
+				Run: func(t statefulTest.T, sut *int, _ struct{}) error {
+					*sut++
+					return nil
+				},
+				Next: func(m int, _ struct{}) int { return m + 1 },
+				Post: func(t statefulTest.T, m int, _ struct{}, err error) {
+					require.NoError(t, err)
+				},
+			},
+		},
+		Invariant: func(t statefulTest.T, m int, sut *int) {
+			require.Equal(t, m, *sut)
+		},
+	}
+	quickcheck.Run(t, quickcheck.Config{NumberOfRuns: 20}, spec.Property())
+}
@@ -0,0 +1,176 @@
+// Package statemachine provides model-based testing of stateful systems.
+//
+// A test is described by a Spec, which consists of a model type M, a factory for the system under test S
+// and a list of commands.
+// Each command has an optional precondition on the model, a generator for its arguments,
+// a function that runs it on the system under test, a function that computes the next model state,
+// and an optional postcondition that compares the result with the model.
+//
+// The runner generates sequences of commands that satisfy the preconditions, executes them and checks
+// the postconditions and invariants.
+// When a sequence fails, it is shrunk by removing commands and shrinking arguments,
+// while keeping all preconditions valid.
+package statemachine
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/peterzeller/go-stateful-test/generator"
+	"github.com/peterzeller/go-stateful-test/pick"
+	"github.com/peterzeller/go-stateful-test/quickcheck"
+	"github.com/peterzeller/go-stateful-test/statefulTest"
+)
+
+// Spec describes a state machine test with model type M and system under test S.
+type Spec[M, S any] struct {
+	// InitialModel returns the model state at the start of a test run.
+	InitialModel func() M
+	// NewSUT creates a new system under test for a test run.
+	// Use t.Cleanup to release resources after the run.
+	NewSUT func(t statefulTest.T) S
+	// Commands that can be executed on the system under test
+	Commands []Command[M, S]
+	// Invariant is checked after every command (optional).
+	// In a ParallelProperty, it is also checked after every command of a candidate linearization of the parallel commands.
+	// The system under test is then already in its state after all parallel commands,
+	// so an invariant for parallel tests should only check the model.
+	Invariant func(t statefulTest.T, model M, sut S)
+}
+
+// Command is a command in a state machine test.
+// Commands are defined using Cmd.
+type Command[M, S any] interface {
+	// precondition checks whether the command can be executed in the given model state
+	precondition(model M) bool
+	// argGenerator returns the generator for arguments in the given model state
+	argGenerator(model M) generator.UntypedGenerator
+	// nextState computes the model state after executing the command
+	nextState(model M, arg generator.UV) M
+	// run executes the command on the system under test and returns the result
+	run(t statefulTest.T, sut S, arg generator.UV) interface{}
+	// postcondition checks the result of the command against the model state before the command
+	postcondition(t statefulTest.T, model M, arg generator.UV, res interface{})
+	// call formats a call of the command with the given argument
+	call(arg generator.UV) string
+}
+
+// Cmd defines a command with arguments of type A and results of type Res.
+//
+// The Next function must not modify the given model, but return a new value instead,
+// because model states are reused while generating and shrinking command sequences.
+type Cmd[M, S, A, Res any] struct {
+	// Name of the command used in logs
+	Name string
+	// Pre is the precondition of the command (optional, default: always enabled)
+	Pre func(model M) bool
+	// Args returns the generator for arguments in the given model state (optional, default: zero value of A).
+	// Use generator.UntypedR to convert generators to the required type.
+	Args func(model M) generator.Generator[A, interface{}]
+	// Run executes the command on the system under test
+	Run func(t statefulTest.T, sut S, arg A) Res
+	// Next computes the model state after the command (optional, default: model is unchanged)
+	Next func(model M, arg A) M
+	// Post checks the result of the command against the model state before the command (optional)
+	Post func(t statefulTest.T, model M, arg A, res Res)
+}
+
+var _ Command[int, int] = Cmd[int, int, int, int]{}
+
+func (c Cmd[M, S, A, Res]) precondition(model M) bool {
+	return c.Pre == nil || c.Pre(model)
+}
+
+func (c Cmd[M, S, A, Res]) argGenerator(model M) generator.UntypedGenerator {
+	if c.Args == nil {
+		var zero A
+		return generator.ToUntyped(generator.Constant(zero))
+	}
+	return generator.ToUntyped(c.Args(model))
+}
+
+func (c Cmd[M, S, A, Res]) nextState(model M, arg generator.UV) M {
+	if c.Next == nil {
+		return model
+	}
+	return c.Next(model, valueOf[A](arg.Value))
+}
+
+func (c Cmd[M, S, A, Res]) run(t statefulTest.T, sut S, arg generator.UV) interface{} {
+	return c.Run(t, sut, valueOf[A](arg.Value))
+}
+
+func (c Cmd[M, S, A, Res]) postcondition(t statefulTest.T, model M, arg generator.UV, res interface{}) {
+	if c.Post != nil {
+		c.Post(t, model, valueOf[A](arg.Value), valueOf[Res](res))
+	}
+}
+
+// valueOf converts an untyped value back to T.
+// A nil interface value, for example a nil error returned by Run, is converted to the zero value of T.
+func valueOf[T any](v interface{}) T {
+	r, ok := v.(T)
+	if !ok && v != nil {
+		panic(fmt.Errorf("unexpected value %v of type %T", v, v))
+	}
+	return r
+}
+
+func (c Cmd[M, S, A, Res]) call(arg generator.UV) string {
+	if c.Args == nil {
+		return fmt.Sprintf("%s()", c.Name)
+	}
+	return fmt.Sprintf("%s(%v)", c.Name, arg.Value)
+}
+
+// Property returns a test function that executes generated command sequences.
+// It can be used with quickcheck.Run and smallcheck.Run.
+func (spec Spec[M, S]) Property() func(t statefulTest.T) {
+	gen := commandSequence(spec)
+	return func(t statefulTest.T) {
+		steps := pick.Val(t, gen)
+		t.Logf("Command sequence:\n%s", formatSteps(steps))
+		sut := spec.NewSUT(t)
+		model := spec.InitialModel()
+		for i, s := range steps {
+			model = s.execute(t, sut, model, i)
+			if spec.Invariant != nil {
+				spec.Invariant(t, model, sut)
+			}
+		}
+	}
+}
+
+// Run executes the state machine test using quickcheck.
+func Run[M, S any](t quickcheck.TestingT, cfg quickcheck.Config, spec Spec[M, S]) {
+	quickcheck.Run(t, cfg, spec.Property())
+}
+
+// step is a command together with its argument
+type step[M, S any] struct {
+	cmd Command[M, S]
+	arg generator.UV
+}
+
+func (s step[M, S]) String() string {
+	return s.cmd.call(s.arg)
+}
+
+// execute runs the step on the system under test and returns the next model state
+func (s step[M, S]) execute(t statefulTest.T, sut S, model M, index int) M {
+	t.Logf("%d: %s", index, s)
+	res := s.cmd.run(t, sut, s.arg)
+	if _, noResult := res.(struct{}); !noResult {
+		t.Logf("   -> %v", res)
+	}
+	s.cmd.postcondition(t, model, s.arg, res)
+	return s.cmd.nextState(model, s.arg)
+}
+
+func formatSteps[M, S any](steps []step[M, S]) string {
+	var res strings.Builder
+	for i, s := range steps {
+		_, _ = fmt.Fprintf(&res, "  %d: %s\n", i, s)
+	}
+	return res.String()
+}
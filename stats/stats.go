@@ -0,0 +1,139 @@
+// Package stats collects labels of test runs and computes the distribution of the labels over all runs.
+// It is used by quickcheck and smallcheck to implement Label, Classify, Collect and Cover.
+package stats
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Labels collects the labels of a single test run.
+type Labels struct {
+	labels []string
+	// required minimum percentage of runs for a label
+	required map[string]float64
+}
+
+// Add adds a label to the run.
+// Adding the same label several times has no additional effect.
+func (l *Labels) Add(label string) {
+	for _, existing := range l.labels {
+		if existing == label {
+			return
+		}
+	}
+	l.labels = append(l.labels, label)
+}
+
+// Require records that the label must be present in at least minPercent percent of the runs.
+func (l *Labels) Require(label string, minPercent float64) {
+	if l.required == nil {
+		l.required = make(map[string]float64)
+	}
+	if p, ok := l.required[label]; !ok || minPercent > p {
+		l.required[label] = minPercent
+	}
+}
+
+// Stats aggregates the labels of several test runs.
+type Stats struct {
+	runs     int
+	counts   map[string]int
+	required map[string]float64
+}
+
+// Record adds the labels of a single run to the statistics.
+func (s *Stats) Record(l *Labels) {
+	if s.counts == nil {
+		s.counts = make(map[string]int)
+		s.required = make(map[string]float64)
+	}
+	s.runs++
+	for _, label := range l.labels {
+		s.counts[label]++
+	}
+	for label, p := range l.required {
+		// required labels appear in the table even if they are never added
+		if _, ok := s.counts[label]; !ok {
+			s.counts[label] = 0
+		}
+		if old, ok := s.required[label]; !ok || p > old {
+			s.required[label] = p
+		}
+	}
+}
+
+// Merge adds the statistics of other to s.
+func (s *Stats) Merge(other *Stats) {
+	if other.counts == nil {
+		s.runs += other.runs
+		return
+	}
+	if s.counts == nil {
+		s.counts = make(map[string]int)
+		s.required = make(map[string]float64)
+	}
+	s.runs += other.runs
+	for label, c := range other.counts {
+		s.counts[label] += c
+	}
+	for label, p := range other.required {
+		if old, ok := s.required[label]; !ok || p > old {
+			s.required[label] = p
+		}
+	}
+}
+
+// Empty returns true if no labels were recorded.
+func (s *Stats) Empty() bool {
+	return len(s.counts) == 0
+}
+
+// Percent returns the percentage of runs that have the label.
+func (s *Stats) Percent(label string) float64 {
+	if s.runs == 0 {
+		return 0
+	}
+	return 100 * float64(s.counts[label]) / float64(s.runs)
+}
+
+// Insufficient returns an error message for each label that is present in fewer runs than required by Cover.
+func (s *Stats) Insufficient() []string {
+	var res []string
+	for _, label := range s.sortedLabels() {
+		if p, ok := s.required[label]; ok && s.Percent(label) < p {
+			res = append(res, fmt.Sprintf("Insufficient coverage: %s in %.1f%% of test runs, expected at least %.1f%%", label, s.Percent(label), p))
+		}
+	}
+	return res
+}
+
+// String formats the distribution of labels as a table, most frequent labels first.
+func (s *Stats) String() string {
+	var res strings.Builder
+	_, _ = fmt.Fprintf(&res, "Labels (%d test runs):\n", s.runs)
+	for _, label := range s.sortedLabels() {
+		_, _ = fmt.Fprintf(&res, "%6.1f%% %s", s.Percent(label), label)
+		if p, ok := s.required[label]; ok {
+			_, _ = fmt.Fprintf(&res, " (required: %.1f%%)", p)
+		}
+		res.WriteRune('\n')
+	}
+	return res.String()
+}
+
+func (s *Stats) sortedLabels() []string {
+	labels := make([]string, 0, len(s.counts))
+	for label := range s.counts {
+		labels = append(labels, label)
+	}
+	sort.Slice(labels, func(i, j int) bool {
+		ci, cj := s.counts[labels[i]], s.counts[labels[j]]
+		if ci != cj {
+			return ci > cj
+		}
+		return labels[i] < labels[j]
+	})
+	return labels
+}
@@ -0,0 +1,48 @@
+package stats
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStats(t *testing.T) {
+	var s Stats
+	for i := 0; i < 4; i++ {
+		var l Labels
+		l.Add("all")
+		l.Add("all")
+		if i == 0 {
+			l.Add("first")
+		}
+		l.Require("first", 50)
+		l.Require("never", 10)
+		s.Record(&l)
+	}
+	require.Equal(t, 100.0, s.Percent("all"))
+	require.Equal(t, 25.0, s.Percent("first"))
+	require.Equal(t, "Labels (4 test runs):\n"+
+		" 100.0% all\n"+
+		"  25.0% first (required: 50.0%)\n"+
+		"   0.0% never (required: 10.0%)\n", s.String())
+	require.Equal(t, []string{
+		"Insufficient coverage: first in 25.0% of test runs, expected at least 50.0%",
+		"Insufficient coverage: never in 0.0% of test runs, expected at least 10.0%",
+	}, s.Insufficient())
+}
+
+func TestStatsMerge(t *testing.T) {
+	var a, b, empty Stats
+	var l1, l2 Labels
+	l1.Add("x")
+	l2.Add("y")
+	l2.Require("x", 20)
+	a.Record(&l1)
+	b.Record(&l2)
+	b.Record(&Labels{})
+	a.Merge(&b)
+	a.Merge(&empty)
+	require.Equal(t, "Labels (3 test runs):\n"+
+		"  33.3% x (required: 20.0%)\n"+
+		"  33.3% y\n", a.String())
+}